@@ -0,0 +1,122 @@
+// Package dap serves the Debug Adapter Protocol, so that editors can debug
+// Grundfunken programs with the interpreter's debugger.
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// request is a message from the editor asking the adapter to do something
+type request struct {
+	Seq       int             `json:"seq"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type response struct {
+	Seq        int    `json:"seq"`
+	Type       string `json:"type"`
+	RequestSeq int    `json:"request_seq"`
+	Success    bool   `json:"success"`
+	Command    string `json:"command"`
+	Message    string `json:"message,omitempty"`
+	Body       any    `json:"body,omitempty"`
+}
+
+type event struct {
+	Seq   int    `json:"seq"`
+	Type  string `json:"type"`
+	Event string `json:"event"`
+	Body  any    `json:"body,omitempty"`
+}
+
+// conn reads and writes messages, each of which is a header giving the
+// length of its content, followed by the content as JSON.  Writes may come
+// from any goroutine.
+type conn struct {
+	r *bufio.Reader
+
+	mu  sync.Mutex
+	w   io.Writer
+	seq int
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{
+		r: bufio.NewReader(r),
+		w: w,
+	}
+}
+
+func (c *conn) read() (*request, error) {
+	header, err := textproto.NewReader(c.r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(c.r, content); err != nil {
+		return nil, err
+	}
+
+	req := &request{}
+	if err := json.Unmarshal(content, req); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return req, nil
+}
+
+func (c *conn) respond(req *request, body any, err error) error {
+	res := &response{
+		Type:       "response",
+		RequestSeq: req.Seq,
+		Success:    err == nil,
+		Command:    req.Command,
+		Body:       body,
+	}
+	if err != nil {
+		res.Message = err.Error()
+	}
+	return c.write(func(seq int) any {
+		res.Seq = seq
+		return res
+	})
+}
+
+func (c *conn) event(name string, body any) error {
+	return c.write(func(seq int) any {
+		return &event{
+			Seq:   seq,
+			Type:  "event",
+			Event: name,
+			Body:  body,
+		}
+	})
+}
+
+// write writes the message made with the next sequence number
+func (c *conn) write(message func(seq int) any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	content, err := json.Marshal(message(c.seq))
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(content)
+	return err
+}
@@ -0,0 +1,475 @@
+package dap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+	"github.com/brandonksides/grundfunken/models/values"
+)
+
+// Serve serves the Debug Adapter Protocol on standard input and output or,
+// if an address is given, to each connection to it, debugging programs
+// launched by the editor with the given options.  The capabilities granted
+// by the editor's launch configuration are added to those of the options.
+func Serve(addr string, opts interpreter.Options) error {
+	if addr == "" {
+		return newSession(newConn(os.Stdin, os.Stdout), opts).serve()
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	for {
+		c, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer c.Close()
+			newSession(newConn(c, c), opts).serve()
+		}()
+	}
+}
+
+// threadID identifies the only thread reported to the editor; the tasks of
+// a program all pause together
+const threadID = 1
+
+// session debugs a single program for an editor
+type session struct {
+	conn *conn
+	opts interpreter.Options
+
+	// launch is the launch request, once it has been received
+	launch     *launchArguments
+	configured bool
+	started    bool
+	debugger   *interpreter.Debugger
+	// breakpoints holds the lines of the breakpoints set by the editor,
+	// by file name, until there is a debugger to set them in
+	breakpoints map[string][]int
+
+	mu      sync.Mutex
+	stopped *interpreter.Stopped
+	// refs holds what each variables reference given to the editor since
+	// evaluation last paused refers to: the bindings of a stack frame, or a
+	// list or object
+	refs []any
+}
+
+type launchArguments struct {
+	Program     string   `json:"program"`
+	Args        []string `json:"args"`
+	StopOnEntry bool     `json:"stopOnEntry"`
+	NoDebug     bool     `json:"noDebug"`
+	// Allow lists the capabilities to grant, as with the -allow flag
+	Allow string `json:"allow"`
+}
+
+func newSession(c *conn, opts interpreter.Options) *session {
+	return &session{
+		conn:        c,
+		opts:        opts,
+		breakpoints: make(map[string][]int),
+	}
+}
+
+func (s *session) serve() error {
+	for {
+		req, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		body, err := s.handle(req)
+		if respondErr := s.conn.respond(req, body, err); respondErr != nil {
+			return respondErr
+		}
+
+		switch req.Command {
+		case "initialize":
+			s.conn.event("initialized", nil)
+		case "disconnect", "terminate":
+			return nil
+		}
+	}
+}
+
+func (s *session) handle(req *request) (any, error) {
+	switch req.Command {
+	case "initialize":
+		return map[string]any{
+			"supportsConfigurationDoneRequest": true,
+			"supportsEvaluateForHovers":        true,
+		}, nil
+	case "launch":
+		args := &launchArguments{}
+		if err := json.Unmarshal(req.Arguments, args); err != nil {
+			return nil, fmt.Errorf("invalid launch arguments: %w", err)
+		}
+		if args.Program == "" {
+			return nil, errors.New("the launch configuration must give the program to debug")
+		}
+		s.launch = args
+		return nil, s.start()
+	case "setBreakpoints":
+		return s.setBreakpoints(req.Arguments)
+	case "setExceptionBreakpoints":
+		return map[string]any{"breakpoints": []any{}}, nil
+	case "configurationDone":
+		s.configured = true
+		return nil, s.start()
+	case "threads":
+		return map[string]any{
+			"threads": []map[string]any{{"id": threadID, "name": "main"}},
+		}, nil
+	case "stackTrace":
+		return s.stackTrace()
+	case "scopes":
+		return s.scopes(req.Arguments)
+	case "variables":
+		return s.variables(req.Arguments)
+	case "evaluate":
+		return s.evaluate(req.Arguments)
+	case "continue":
+		s.resume((*interpreter.Debugger).Continue)
+		return map[string]any{"allThreadsContinued": true}, nil
+	case "next":
+		s.resume((*interpreter.Debugger).StepOver)
+		return nil, nil
+	case "stepIn":
+		s.resume((*interpreter.Debugger).StepIn)
+		return nil, nil
+	case "stepOut":
+		s.resume((*interpreter.Debugger).StepOut)
+		return nil, nil
+	case "pause":
+		if s.debugger != nil {
+			s.debugger.Pause()
+		}
+		return nil, nil
+	case "disconnect", "terminate":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported request \"%s\"", req.Command)
+	}
+}
+
+// start starts the program once it has been launched and the editor has
+// finished setting breakpoints
+func (s *session) start() error {
+	if s.launch == nil || !s.configured || s.started {
+		return nil
+	}
+	s.started = true
+
+	opts := s.opts
+	if s.launch.Allow != "" {
+		grants, err := interpreter.ParseGrants(s.launch.Allow)
+		if err != nil {
+			return err
+		}
+		for c, scopes := range s.opts.Grants {
+			grants[c] = append(grants[c], scopes...)
+		}
+		opts.Grants = grants
+	}
+	opts.Args = s.launch.Args
+	// standard input carries the protocol, rather than input for the
+	// program
+	opts.Stdin = strings.NewReader("")
+	opts.Stdout = outputWriter{s.conn, "stdout"}
+
+	if !s.launch.NoDebug {
+		s.debugger = interpreter.NewDebugger(s.launch.StopOnEntry)
+		s.debugger.OnStop = s.onStop
+		for file, lines := range s.breakpoints {
+			s.debugger.SetBreakpoints(file, lines)
+		}
+		opts.Debugger = s.debugger
+	}
+
+	go func() {
+		val, lines, err := interpreter.Interpret(context.Background(), s.launch.Program, opts)
+		exitCode := 0
+		if err != nil {
+			s.conn.event("output", map[string]any{
+				"category": "stderr",
+				"output":   "Error: " + interpreter.FormatError(err, lines),
+			})
+			exitCode = 1
+		} else {
+			s.conn.event("output", map[string]any{
+				"category": "console",
+				"output":   fmt.Sprintf("Result: %v\n", val),
+			})
+		}
+		s.conn.event("exited", map[string]any{"exitCode": exitCode})
+		s.conn.event("terminated", nil)
+	}()
+	return nil
+}
+
+func (s *session) onStop(stopped *interpreter.Stopped) {
+	s.mu.Lock()
+	s.stopped = stopped
+	s.refs = nil
+	s.mu.Unlock()
+
+	s.conn.event("stopped", map[string]any{
+		"reason":            stopped.Reason,
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	})
+}
+
+func (s *session) resume(resume func(*interpreter.Debugger)) {
+	if s.debugger == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.stopped = nil
+	s.refs = nil
+	s.mu.Unlock()
+	resume(s.debugger)
+}
+
+func (s *session) setBreakpoints(arguments json.RawMessage) (any, error) {
+	args := struct {
+		Source struct {
+			Path string `json:"path"`
+		} `json:"source"`
+		Breakpoints []struct {
+			Line int `json:"line"`
+		} `json:"breakpoints"`
+	}{}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid setBreakpoints arguments: %w", err)
+	}
+
+	// files are known to the interpreter by name
+	file := filepath.Base(args.Source.Path)
+	lines := make([]int, 0, len(args.Breakpoints))
+	set := make([]map[string]any, 0, len(args.Breakpoints))
+	for _, bp := range args.Breakpoints {
+		lines = append(lines, bp.Line)
+		set = append(set, map[string]any{"verified": true, "line": bp.Line})
+	}
+
+	s.breakpoints[file] = lines
+	if s.debugger != nil {
+		s.debugger.SetBreakpoints(file, lines)
+	}
+	return map[string]any{"breakpoints": set}, nil
+}
+
+func (s *session) stackTrace() (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped == nil {
+		return nil, errors.New("the program is running")
+	}
+
+	frames := make([]map[string]any, 0, len(s.stopped.Stack))
+	for i, frame := range s.stopped.Stack {
+		name := frame.Function
+		if name == "" {
+			name = "<main>"
+		}
+		stackFrame := map[string]any{
+			// frame ids start from one, like variables references
+			"id":     i + 1,
+			"name":   name,
+			"line":   frame.SourceLocation.LineNumber + 1,
+			"column": frame.SourceLocation.ColumnNumber + 1,
+		}
+		if frame.Path != "" {
+			path, err := filepath.Abs(frame.Path)
+			if err != nil {
+				path = frame.Path
+			}
+			stackFrame["source"] = map[string]any{
+				"name": frame.SourceLocation.File,
+				"path": path,
+			}
+		}
+		frames = append(frames, stackFrame)
+	}
+	return map[string]any{
+		"stackFrames": frames,
+		"totalFrames": len(frames),
+	}, nil
+}
+
+// frame returns the stack frame with the given id, which must be called
+// with the lock held
+func (s *session) frame(id int) (*interpreter.StackFrame, error) {
+	if s.stopped == nil {
+		return nil, errors.New("the program is running")
+	}
+	if id < 1 || id > len(s.stopped.Stack) {
+		return nil, fmt.Errorf("no stack frame %d", id)
+	}
+	return &s.stopped.Stack[id-1], nil
+}
+
+func (s *session) scopes(arguments json.RawMessage) (any, error) {
+	args := struct {
+		FrameID int `json:"frameId"`
+	}{}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid scopes arguments: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	frame, err := s.frame(args.FrameID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"scopes": []map[string]any{{
+			"name":               "Locals",
+			"variablesReference": s.ref(frame.Bindings),
+			"expensive":          false,
+		}},
+	}, nil
+}
+
+// ref returns a new variables reference to a value, which must be called
+// with the lock held
+func (s *session) ref(v any) int {
+	s.refs = append(s.refs, v)
+	return len(s.refs)
+}
+
+func (s *session) variables(arguments json.RawMessage) (any, error) {
+	args := struct {
+		VariablesReference int `json:"variablesReference"`
+	}{}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid variables arguments: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if args.VariablesReference < 1 || args.VariablesReference > len(s.refs) {
+		return nil, fmt.Errorf("no variables with reference %d", args.VariablesReference)
+	}
+
+	vars := make([]map[string]any, 0)
+	switch v := s.refs[args.VariablesReference-1].(type) {
+	case []interpreter.Binding:
+		for _, binding := range v {
+			vars = append(vars, s.variable(binding.Name, binding.Value))
+		}
+	case []any:
+		for i, elem := range v {
+			vars = append(vars, s.variable("["+strconv.Itoa(i)+"]", elem))
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			vars = append(vars, s.variable(key, v[key]))
+		}
+	}
+	return map[string]any{"variables": vars}, nil
+}
+
+// variable describes a value to the editor, with a reference to its
+// elements or fields if it is a list or object, which must be called with
+// the lock held
+func (s *session) variable(name string, v any) map[string]any {
+	ret := map[string]any{
+		"name":               name,
+		"value":              values.Format(v),
+		"variablesReference": 0,
+	}
+	switch v := v.(type) {
+	case []any:
+		if len(v) > 0 {
+			ret["variablesReference"] = s.ref(v)
+		}
+	case map[string]any:
+		if len(v) > 0 {
+			ret["variablesReference"] = s.ref(v)
+		}
+	}
+	return ret
+}
+
+// evaluate looks up the value of an identifier, as when hovering over it;
+// other expressions are not evaluated
+func (s *session) evaluate(arguments json.RawMessage) (any, error) {
+	args := struct {
+		Expression string `json:"expression"`
+		FrameID    int    `json:"frameId"`
+	}{}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid evaluate arguments: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if args.FrameID == 0 {
+		args.FrameID = 1
+	}
+	frame, err := s.frame(args.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(args.Expression)
+	for _, binding := range frame.Bindings {
+		if binding.Name == name {
+			v := s.variable(name, binding.Value)
+			return map[string]any{
+				"result":             v["value"],
+				"variablesReference": v["variablesReference"],
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("no binding \"%s\"; only identifiers can be evaluated", name)
+}
+
+// outputWriter sends what the program prints to the editor
+type outputWriter struct {
+	conn     *conn
+	category string
+}
+
+func (ow outputWriter) Write(p []byte) (int, error) {
+	err := ow.conn.event("output", map[string]any{
+		"category": ow.category,
+		"output":   string(p),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,237 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+)
+
+// client drives a session as an editor would, over in-memory pipes
+type client struct {
+	t        *testing.T
+	w        io.Writer
+	messages chan map[string]any
+	seq      int
+	// events holds the events read while waiting for responses, until
+	// they are waited for
+	events []map[string]any
+}
+
+func newClient(t *testing.T, opts interpreter.Options) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	t.Cleanup(func() {
+		clientOut.Close()
+		clientIn.Close()
+	})
+
+	go func() {
+		newSession(newConn(serverIn, serverOut), opts).serve()
+		serverOut.Close()
+	}()
+
+	c := &client{
+		t:        t,
+		w:        clientOut,
+		messages: make(chan map[string]any),
+	}
+	go c.read(bufio.NewReader(clientIn))
+	return c
+}
+
+// read reads the messages of the session until it ends
+func (c *client) read(r *bufio.Reader) {
+	defer close(c.messages)
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			return
+		}
+		content := make([]byte, length)
+		if _, err := io.ReadFull(r, content); err != nil {
+			return
+		}
+
+		message := make(map[string]any)
+		if err := json.Unmarshal(content, &message); err != nil {
+			return
+		}
+		c.messages <- message
+	}
+}
+
+func (c *client) next() map[string]any {
+	c.t.Helper()
+
+	select {
+	case message, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("the session ended")
+		}
+		return message
+	case <-time.After(10 * time.Second):
+		c.t.Fatal("timed out waiting for a message")
+		return nil
+	}
+}
+
+// request sends a request, returning the body of its response, which must
+// be successful
+func (c *client) request(command string, arguments any) map[string]any {
+	c.t.Helper()
+
+	c.seq++
+	content, err := json.Marshal(map[string]any{
+		"seq":       c.seq,
+		"type":      "request",
+		"command":   command,
+		"arguments": arguments,
+	})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(content), content); err != nil {
+		c.t.Fatal(err)
+	}
+
+	for {
+		message := c.next()
+		if message["type"] == "event" {
+			c.events = append(c.events, message)
+			continue
+		}
+		if message["request_seq"] != float64(c.seq) {
+			c.t.Fatalf("unexpected response %v to %s", message, command)
+		}
+		if message["success"] != true {
+			c.t.Fatalf("%s failed: %v", command, message["message"])
+		}
+		body, _ := message["body"].(map[string]any)
+		return body
+	}
+}
+
+// event waits for the event with the given name, returning its body, and
+// discarding any other events before it
+func (c *client) event(name string) map[string]any {
+	c.t.Helper()
+
+	for {
+		var message map[string]any
+		if len(c.events) > 0 {
+			message, c.events = c.events[0], c.events[1:]
+		} else {
+			message = c.next()
+		}
+		if message["type"] == "event" && message["event"] == name {
+			body, _ := message["body"].(map[string]any)
+			return body
+		}
+	}
+}
+
+// stoppedAt waits for evaluation to pause, checking the reason and the
+// line of the innermost frame, and returns the variables of that frame
+func (c *client) stoppedAt(reason string, line int) map[string]string {
+	c.t.Helper()
+
+	stopped := c.event("stopped")
+	if stopped["reason"] != reason {
+		c.t.Errorf("expected to stop for %s; stopped for %v", reason, stopped["reason"])
+	}
+
+	trace := c.request("stackTrace", map[string]any{"threadId": threadID})
+	frames, _ := trace["stackFrames"].([]any)
+	if len(frames) == 0 {
+		c.t.Fatalf("expected stack frames; got %v", trace)
+	}
+	top := frames[0].(map[string]any)
+	if top["line"] != float64(line) {
+		c.t.Errorf("expected to stop at line %d; stopped at line %v", line, top["line"])
+	}
+
+	scopes := c.request("scopes", map[string]any{"frameId": top["id"]})
+	locals := scopes["scopes"].([]any)[0].(map[string]any)
+	vars := c.request("variables", map[string]any{"variablesReference": locals["variablesReference"]})
+
+	ret := make(map[string]string)
+	for _, v := range vars["variables"].([]any) {
+		variable := v.(map[string]any)
+		ret[variable["name"].(string)] = variable["value"].(string)
+	}
+	return ret
+}
+
+func TestSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "double.gf")
+	src := `let
+    double = func(n int) int
+        n * 2,
+    x = double(3),
+    y = x + 1
+in
+    [x, y]
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newClient(t, interpreter.Options{Grants: make(interpreter.Grants)})
+
+	c.request("initialize", map[string]any{"adapterID": "grundfunken"})
+	c.event("initialized")
+	c.request("launch", map[string]any{"program": path})
+
+	set := c.request("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": path},
+		"breakpoints": []map[string]any{{"line": 3}},
+	})
+	want := []any{map[string]any{"verified": true, "line": float64(3)}}
+	if !reflect.DeepEqual(set["breakpoints"], want) {
+		t.Errorf("expected breakpoints %v; got %v", want, set["breakpoints"])
+	}
+	c.request("configurationDone", nil)
+
+	// paused in the body of double, called from the binding of x
+	vars := c.stoppedAt("breakpoint", 3)
+	if vars["n"] != "3" {
+		t.Errorf("expected n = 3; got %v", vars)
+	}
+	if _, ok := vars["x"]; ok {
+		t.Errorf("expected x to be unbound in double; got %v", vars)
+	}
+
+	// stepping over the rest of the call reaches the binding of y
+	c.request("next", map[string]any{"threadId": threadID})
+	vars = c.stoppedAt("step", 5)
+	if vars["x"] != "6" {
+		t.Errorf("expected x = 6; got %v", vars)
+	}
+
+	c.request("continue", map[string]any{"threadId": threadID})
+	output := c.event("output")
+	if output["output"] != "Result: [6 7]\n" {
+		t.Errorf("unexpected output %v", output["output"])
+	}
+	exited := c.event("exited")
+	if exited["exitCode"] != float64(0) {
+		t.Errorf("expected exit code 0; got %v", exited["exitCode"])
+	}
+	c.event("terminated")
+
+	c.request("disconnect", nil)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brandonksides/grundfunken/dap"
+	"github.com/brandonksides/grundfunken/interpreter"
+	"github.com/brandonksides/grundfunken/models/values"
+)
+
+// debug runs the debug subcommand, which debugs the program in the file
+// given as its argument from the terminal or, with -dap, serves the Debug
+// Adapter Protocol, returning the exit code
+func debug(args []string) int {
+	var serveDAP bool
+	var listen string
+	opts := interpreter.Options{Grants: make(interpreter.Grants)}
+	fs := flag.NewFlagSet("debug", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s debug [flags] <file> [args...]\n       %s debug -dap [-listen addr]\n", os.Args[0], os.Args[0])
+		fs.PrintDefaults()
+	}
+	finishOptions := bindOptionFlags(fs, &opts)
+	fs.BoolVar(&serveDAP, "dap", false, "Serve the Debug Adapter Protocol on standard input and output, for editors, rather than debugging from the terminal")
+	fs.StringVar(&listen, "listen", "", "With -dap, serve the Debug Adapter Protocol to each connection to this address instead")
+	fs.Parse(args)
+	finishOptions()
+
+	if serveDAP {
+		if err := dap.Serve(listen, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "debug adapter failed: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	opts.Args = fs.Args()[1:]
+
+	return debugInTerminal(fs.Arg(0), opts, os.Stdin, os.Stdout)
+}
+
+const debugHelp = `Commands:
+  c, continue          resume until the next breakpoint
+  s, step              step to the next line, into any function called
+  n, next              step to the next line, over any function called
+  o, out               step out of the current function
+  b, break [file:]line set a breakpoint
+  clear [file:]line    clear a breakpoint
+  breakpoints          list the breakpoints
+  bt, stack            show the calls in progress
+  l, locals [frame]    show the bindings of a call, the innermost by default
+  p, print name        show the value of a binding of the innermost call
+  q, quit              stop debugging
+`
+
+// debugInTerminal debugs the program in the file at the given path,
+// reading commands from in, and writing to out, whenever it is paused
+func debugInTerminal(path string, opts interpreter.Options, in io.Reader, out io.Writer) int {
+	stops := make(chan *interpreter.Stopped)
+	debugger := interpreter.NewDebugger(true)
+	debugger.OnStop = func(stopped *interpreter.Stopped) {
+		stops <- stopped
+	}
+	opts.Debugger = debugger
+
+	type result struct {
+		val   any
+		lines map[string][]string
+		err   error
+	}
+	done := make(chan result)
+	go func() {
+		val, lines, err := interpreter.Interpret(context.Background(), path, opts)
+		done <- result{val, lines, err}
+	}()
+
+	breakpoints := make(map[string][]int)
+	commands := bufio.NewScanner(in)
+	fmt.Fprint(out, "Type \"help\" for a list of commands.\n")
+	for {
+		var stopped *interpreter.Stopped
+		select {
+		case r := <-done:
+			if r.err != nil {
+				fmt.Fprint(out, "Error: "+interpreter.FormatError(r.err, r.lines))
+				return 1
+			}
+			fmt.Fprintf(out, "Result: %v\n", r.val)
+			return 0
+		case stopped = <-stops:
+		}
+
+		top := stopped.Stack[0]
+		fmt.Fprintf(out, "Stopped (%s) in %s\n", stopped.Reason, top)
+		if line := debugger.SourceLine(top.SourceLocation); line != "" {
+			fmt.Fprintf(out, "%5d  %s\n", top.SourceLocation.LineNumber+1, line)
+		}
+
+		for resumed := false; !resumed; {
+			fmt.Fprint(out, "(debug) ")
+			if !commands.Scan() {
+				return 0
+			}
+			fields := strings.Fields(commands.Text())
+			if len(fields) == 0 {
+				continue
+			}
+
+			switch fields[0] {
+			case "c", "continue":
+				debugger.Continue()
+				resumed = true
+			case "s", "step":
+				debugger.StepIn()
+				resumed = true
+			case "n", "next":
+				debugger.StepOver()
+				resumed = true
+			case "o", "out":
+				debugger.StepOut()
+				resumed = true
+			case "b", "break", "clear":
+				if len(fields) != 2 {
+					fmt.Fprintf(out, "usage: %s [file:]line\n", fields[0])
+					continue
+				}
+				file, line, err := parseBreakpoint(fields[1], top.SourceLocation.File)
+				if err != nil {
+					fmt.Fprintln(out, err)
+					continue
+				}
+				if fields[0] == "clear" {
+					breakpoints[file] = removeLine(breakpoints[file], line)
+				} else {
+					breakpoints[file] = append(removeLine(breakpoints[file], line), line)
+				}
+				debugger.SetBreakpoints(file, breakpoints[file])
+			case "breakpoints":
+				files := make([]string, 0, len(breakpoints))
+				for file := range breakpoints {
+					files = append(files, file)
+				}
+				sort.Strings(files)
+				for _, file := range files {
+					lines := append([]int{}, breakpoints[file]...)
+					sort.Ints(lines)
+					for _, line := range lines {
+						fmt.Fprintf(out, "%s:%d\n", file, line)
+					}
+				}
+			case "bt", "stack":
+				for i, frame := range stopped.Stack {
+					fmt.Fprintf(out, "%3d  %s\n", i, frame)
+				}
+			case "l", "locals":
+				i := 0
+				if len(fields) > 1 {
+					var err error
+					i, err = strconv.Atoi(fields[1])
+					if err != nil || i < 0 || i >= len(stopped.Stack) {
+						fmt.Fprintf(out, "no frame %s\n", fields[1])
+						continue
+					}
+				}
+				for _, binding := range stopped.Stack[i].Bindings {
+					fmt.Fprintf(out, "%s = %s\n", binding.Name, values.Format(binding.Value))
+				}
+			case "p", "print":
+				if len(fields) != 2 {
+					fmt.Fprintln(out, "usage: print name")
+					continue
+				}
+				found := false
+				for _, binding := range top.Bindings {
+					if binding.Name == fields[1] {
+						fmt.Fprintln(out, values.Format(binding.Value))
+						found = true
+					}
+				}
+				if !found {
+					fmt.Fprintf(out, "no binding \"%s\"\n", fields[1])
+				}
+			case "q", "quit":
+				return 0
+			case "h", "help":
+				fmt.Fprint(out, debugHelp)
+			default:
+				fmt.Fprintf(out, "unknown command \"%s\"; type \"help\" for a list of commands\n", fields[0])
+			}
+		}
+	}
+}
+
+// parseBreakpoint parses the location of a breakpoint, as a line of the
+// given file or as file:line
+func parseBreakpoint(s string, file string) (string, int, error) {
+	lineStr := s
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		file = filepath.Base(s[:i])
+		lineStr = s[i+1:]
+	}
+
+	line, err := strconv.Atoi(lineStr)
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("invalid line \"%s\"", lineStr)
+	}
+	return file, line, nil
+}
+
+func removeLine(lines []int, line int) []int {
+	ret := make([]int, 0, len(lines))
+	for _, l := range lines {
+		if l != line {
+			ret = append(ret, l)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+)
+
+// doc runs the doc subcommand, which documents the module in the file
+// given as its argument, returning the exit code
+func doc(args []string) int {
+	var format, outputPath string
+	fs := flag.NewFlagSet("doc", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s doc [flags] <file>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&format, "format", "markdown", "Format of the documentation (one of markdown, html)")
+	fs.StringVar(&outputPath, "output", "", "Path to write the documentation to, rather than standard output")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	var write func(*interpreter.ModuleDoc, io.Writer) error
+	switch format {
+	case "markdown":
+		write = (*interpreter.ModuleDoc).WriteMarkdown
+	case "html":
+		write = (*interpreter.ModuleDoc).WriteHTML
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format \"%s\"; expected markdown or html\n", format)
+		return 2
+	}
+
+	moduleDoc, lines, err := interpreter.Document(fs.Arg(0))
+	if err != nil {
+		report(err, lines)
+		return 1
+	}
+
+	var output io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create output file: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		output = f
+	}
+
+	if err := write(moduleDoc, output); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write documentation: %v\n", err)
+		return 1
+	}
+	return 0
+}
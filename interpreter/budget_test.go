@@ -0,0 +1,31 @@
+package interpreter_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+	"github.com/brandonksides/grundfunken/models"
+)
+
+// TestStepLimitStopsInfiniteSequence checks that converting an infinite
+// sequence to a list is stopped by the step limit, rather than running
+// until memory runs out
+func TestStepLimitStopsInfiniteSequence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infinite.gf")
+	src := "toList(iterate(0, func(n int) int n + 1))\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := interpreter.Options{Grants: make(interpreter.Grants)}
+	opts.Budget.MaxSteps = 1000
+
+	_, _, err := interpreter.Interpret(context.Background(), path, opts)
+	if !errors.Is(err, models.ErrStepLimitExceeded) {
+		t.Fatalf("expected the step limit to be exceeded; got %v", err)
+	}
+}
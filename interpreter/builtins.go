@@ -0,0 +1,701 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/models/values"
+	"github.com/brandonksides/grundfunken/parser"
+)
+
+type BuiltinFunction struct {
+	// name is the identifier the builtin is bound to
+	name string
+	args []types.Arg
+	ret  types.Type
+	Fn   func(context.Context, []any) (any, error)
+}
+
+func Builtin(args []types.Arg, ret types.Type, fn func(context.Context, []any) (any, error)) types.Function {
+	return &BuiltinFunction{
+		args: args,
+		ret:  ret,
+		Fn:   fn,
+	}
+}
+
+var _ types.Function = &BuiltinFunction{}
+
+func (f BuiltinFunction) Call(ctx context.Context, args []any) (ret any, err error) {
+	ctx, exit := expressions.EnterCall(ctx, f.name, nil, args)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		exit(ret, err)
+	}()
+
+	if len(args) > len(f.args) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(f.args), len(args))
+	}
+	return f.Fn(ctx, args)
+}
+
+func (f BuiltinFunction) Args() []types.Arg {
+	return f.args
+}
+
+func (f BuiltinFunction) Return() types.Type {
+	return f.ret
+}
+
+// formatIntBuiltin makes a builtin formatting an int in the given base, in
+// the same form as a literal
+func formatIntBuiltin(base int) *BuiltinFunction {
+	return &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "n",
+			Type: types.PrimitiveTypeInt,
+		}},
+		ret: types.PrimitiveTypeString,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			return values.FormatInt(args[0], base), nil
+		},
+	}
+}
+
+// floatToInt makes a builtin converting a float to an int by rounding it
+// with the given function; floats beyond the range of ints are an error
+func floatToInt(round func(float64) float64) *BuiltinFunction {
+	return &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "f",
+			Type: types.PrimitiveTypeFloat,
+		}},
+		ret: types.PrimitiveTypeInt,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			f := round(args[0].(float64))
+			if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return nil, fmt.Errorf("%s is out of the range of ints", values.FormatFloat(f))
+			}
+			return int(f), nil
+		},
+	}
+}
+
+var builtins = map[string]any{
+	"len": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "list",
+			Type: types.List(types.PrimitiveTypeAny),
+		}},
+		ret: types.PrimitiveTypeInt,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			list := args[0].([]any)
+			return len(list), nil
+		},
+	},
+	"range": &BuiltinFunction{
+		args: []types.Arg{
+			{
+				Name: "start",
+				Type: types.PrimitiveTypeInt,
+			},
+			{
+				Name: "end",
+				Type: types.PrimitiveTypeInt,
+			},
+		},
+		ret: types.List(types.PrimitiveTypeInt),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			start := args[0].(int)
+			end := args[1].(int)
+
+			if err := expressions.Allocate(ctx, end-start); err != nil {
+				return nil, err
+			}
+
+			ret := make([]any, 0, end-start)
+			for start < end {
+				ret = append(ret, start)
+				start++
+			}
+
+			return ret, nil
+		},
+	},
+	"toString": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "val",
+			Type: types.PrimitiveTypeAny,
+		}},
+		ret: types.PrimitiveTypeString,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			return values.Format(args[0]), nil
+		},
+	},
+	"prepend": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "item",
+			Type: types.PrimitiveTypeAny,
+		}, {
+			Name: "list",
+			Type: types.List(types.PrimitiveTypeAny),
+		}},
+		ret: types.List(types.PrimitiveTypeAny),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			list := args[1].([]any)
+			if err := expressions.Allocate(ctx, len(list)+1); err != nil {
+				return nil, err
+			}
+
+			return append([]any{args[0]}, list...), nil
+		},
+	},
+	"append": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "list",
+			Type: types.List(types.PrimitiveTypeAny),
+		}, {
+			Name: "item",
+			Type: types.PrimitiveTypeAny,
+		}},
+		ret: types.List(types.PrimitiveTypeAny),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			list := args[0].([]any)
+			if err := expressions.Allocate(ctx, len(list)+1); err != nil {
+				return nil, err
+			}
+
+			newList := make([]any, len(list))
+			copy(newList, list)
+
+			return append(newList, args[1]), nil
+		},
+	},
+	"concat": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "list1",
+			Type: types.List(types.PrimitiveTypeAny),
+		}, {
+			Name: "list2",
+			Type: types.List(types.PrimitiveTypeAny),
+		}},
+		ret: types.List(types.PrimitiveTypeAny),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			list1 := args[0].([]any)
+			list2 := args[1].([]any)
+			if err := expressions.Allocate(ctx, len(list1)+len(list2)); err != nil {
+				return nil, err
+			}
+
+			newList := make([]any, len(list1))
+			copy(newList, list1)
+
+			return append(newList, list2...), nil
+		},
+	},
+	"concatStr": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "str1",
+			Type: types.PrimitiveTypeString,
+		}, {
+			Name: "str2",
+			Type: types.PrimitiveTypeString,
+		}},
+		ret: types.PrimitiveTypeString,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			str1 := args[0].(string)
+			str2 := args[1].(string)
+			if err := expressions.Allocate(ctx, len(str1)+len(str2)); err != nil {
+				return nil, err
+			}
+
+			return str1 + str2, nil
+		},
+	},
+	"atStr": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "str",
+			Type: types.PrimitiveTypeString,
+		}, {
+			Name: "index",
+			Type: types.PrimitiveTypeInt,
+		}},
+		ret: types.PrimitiveTypeString,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			str := []rune(args[0].(string))
+			index := args[1].(int)
+			if index < 0 || index >= len(str) {
+				return nil, fmt.Errorf("index out of bounds (%d); len is %d", index, len(str))
+			}
+			return string(str[index]), nil
+		},
+	},
+	"lenStr": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "str",
+			Type: types.PrimitiveTypeString,
+		}},
+		ret: types.PrimitiveTypeInt,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			str := args[0].(string)
+			return utf8.RuneCountInString(str), nil
+		},
+	},
+	"sliceStr": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "str",
+			Type: types.PrimitiveTypeString,
+		}, {
+			Name: "start",
+			Type: types.PrimitiveTypeInt,
+		}, {
+			Name: "end",
+			Type: types.PrimitiveTypeInt,
+		}},
+		ret: types.PrimitiveTypeString,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			str := []rune(args[0].(string))
+			start := args[1].(int)
+			end := args[2].(int)
+			if start < 0 {
+				start = len(str) + start + 1
+			}
+			if start < 0 || start > len(str) {
+				return nil, fmt.Errorf("start index out of bounds (%d); len is %d", start, len(str))
+			}
+			if end < 0 {
+				end = len(str) + end + 1
+			}
+			if end < start || end > len(str) {
+				return nil, fmt.Errorf("end index out of bounds (%d); len is %d", end, len(str))
+			}
+
+			return string(str[start:end]), nil
+		},
+	},
+	"bytes": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "str",
+			Type: types.PrimitiveTypeString,
+		}},
+		ret: types.List(types.PrimitiveTypeInt),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			str := args[0].(string)
+			if err := expressions.Allocate(ctx, len(str)); err != nil {
+				return nil, err
+			}
+
+			ret := make([]any, 0, len(str))
+			for i := 0; i < len(str); i++ {
+				ret = append(ret, int(str[i]))
+			}
+			return ret, nil
+		},
+	},
+	"runes": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "str",
+			Type: types.PrimitiveTypeString,
+		}},
+		ret: types.List(types.PrimitiveTypeInt),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			str := args[0].(string)
+			if err := expressions.Allocate(ctx, utf8.RuneCountInString(str)); err != nil {
+				return nil, err
+			}
+
+			ret := make([]any, 0, len(str))
+			for _, r := range str {
+				ret = append(ret, int(r))
+			}
+			return ret, nil
+		},
+	},
+	"fromBytes": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "bytes",
+			Type: types.List(types.PrimitiveTypeInt),
+		}},
+		ret: types.PrimitiveTypeString,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			list := args[0].([]any)
+			if err := expressions.Allocate(ctx, len(list)); err != nil {
+				return nil, err
+			}
+
+			ret := make([]byte, 0, len(list))
+			for i, elem := range list {
+				b, ok := elem.(int)
+				if !ok || b < 0 || b > 255 {
+					return nil, fmt.Errorf("element %d (%v) is not a byte", i, elem)
+				}
+				ret = append(ret, byte(b))
+			}
+			if !utf8.Valid(ret) {
+				return nil, fmt.Errorf("bytes are not valid UTF-8")
+			}
+			return string(ret), nil
+		},
+	},
+	"fromRunes": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "runes",
+			Type: types.List(types.PrimitiveTypeInt),
+		}},
+		ret: types.PrimitiveTypeString,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			list := args[0].([]any)
+			if err := expressions.Allocate(ctx, len(list)); err != nil {
+				return nil, err
+			}
+
+			var sb strings.Builder
+			for i, elem := range list {
+				r, ok := elem.(int)
+				if !ok || r < 0 || r > utf8.MaxRune || !utf8.ValidRune(rune(r)) {
+					return nil, fmt.Errorf("element %d (%v) is not a unicode code point", i, elem)
+				}
+				sb.WriteRune(rune(r))
+			}
+			return sb.String(), nil
+		},
+	},
+	"parseInt": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "str",
+			Type: types.PrimitiveTypeString,
+		}},
+		ret: types.PrimitiveTypeInt,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			str := args[0].(string)
+			num := new(big.Int)
+			_, err := fmt.Sscanf(str, "%d", num)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse int from string \"%s\"", str)
+			}
+
+			ret := values.NormalizeInt(num)
+			if _, ok := ret.(int); !ok && expressions.IntOverflowMode(ctx) != expressions.IntOverflowPromote {
+				return nil, fmt.Errorf("int parsed from string \"%s\" overflows int", str)
+			}
+			return ret, nil
+		},
+	},
+	"parseIntRadix": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "str",
+			Type: types.PrimitiveTypeString,
+		}, {
+			Name: "radix",
+			Type: types.PrimitiveTypeInt,
+		}},
+		ret: types.PrimitiveTypeInt,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			str := args[0].(string)
+			ret, err := values.ParseInt(str, args[1].(int))
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := ret.(int); !ok && expressions.IntOverflowMode(ctx) != expressions.IntOverflowPromote {
+				return nil, fmt.Errorf("int parsed from string \"%s\" overflows int", str)
+			}
+			return ret, nil
+		},
+	},
+	"toHex":    formatIntBuiltin(16),
+	"toBinary": formatIntBuiltin(2),
+	"toFloat": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "n",
+			Type: types.PrimitiveTypeInt,
+		}},
+		ret: types.PrimitiveTypeFloat,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			return float64(args[0].(int)), nil
+		},
+	},
+	"round":    floatToInt(math.Round),
+	"floor":    floatToInt(math.Floor),
+	"truncate": floatToInt(math.Trunc),
+	"toSeq": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "list",
+			Type: types.List(types.Var("T")),
+		}},
+		ret: types.Seq(types.Var("T")),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			list := args[0].([]any)
+
+			var elemType types.Type = types.PrimitiveTypeAny
+			if listType, err := types.TypeOf(list); err == nil {
+				elemType = listType.(types.ListType).ElementType
+			}
+
+			return values.SeqOf(elemType, list), nil
+		},
+	},
+	"toList": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "seq",
+			Type: types.Seq(types.Var("T")),
+		}},
+		ret: types.List(types.Var("T")),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			seq := args[0].(types.Sequence)
+
+			ret := make([]any, 0)
+			err := seq.Iterate(ctx, func(elem any) (bool, error) {
+				if err := expressions.Allocate(ctx, len(ret)+1); err != nil {
+					return false, err
+				}
+				ret = append(ret, elem)
+				return true, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return ret, nil
+		},
+	},
+	"iterate": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "seed",
+			Type: types.Var("T"),
+		}, {
+			Name: "next",
+			Type: types.Func([]types.Type{types.Var("T")}, types.Var("T")),
+		}},
+		ret: types.Seq(types.Var("T")),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			seed := args[0]
+			next := args[1].(types.Function)
+
+			return values.NewSeq(next.Return(), func(ctx context.Context, yield func(any) (bool, error)) error {
+				cur := seed
+				for {
+					if err := expressions.Step(ctx, nil); err != nil {
+						return err
+					}
+
+					more, err := yield(cur)
+					if err != nil || !more {
+						return err
+					}
+
+					cur, err = next.Call(ctx, []any{cur})
+					if err != nil {
+						return err
+					}
+				}
+			}), nil
+		},
+	},
+	"take": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "seq",
+			Type: types.Seq(types.Var("T")),
+		}, {
+			Name: "n",
+			Type: types.PrimitiveTypeInt,
+		}},
+		ret: types.Seq(types.Var("T")),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			seq := args[0].(types.Sequence)
+			n := args[1].(int)
+
+			return values.NewSeq(seq.ElementType(), func(ctx context.Context, yield func(any) (bool, error)) error {
+				if n <= 0 {
+					return nil
+				}
+
+				taken := 0
+				return seq.Iterate(ctx, func(elem any) (bool, error) {
+					taken++
+					more, err := yield(elem)
+					return more && taken < n, err
+				})
+			}), nil
+		},
+	},
+	"takeWhile": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "seq",
+			Type: types.Seq(types.Var("T")),
+		}, {
+			Name: "condition",
+			Type: types.Func([]types.Type{types.Var("T")}, types.PrimitiveTypeBool),
+		}},
+		ret: types.Seq(types.Var("T")),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			seq := args[0].(types.Sequence)
+			condition := args[1].(types.Function)
+
+			return values.NewSeq(seq.ElementType(), func(ctx context.Context, yield func(any) (bool, error)) error {
+				return seq.Iterate(ctx, func(elem any) (bool, error) {
+					ok, err := condition.Call(ctx, []any{elem})
+					if err != nil {
+						return false, err
+					}
+					if ok != true {
+						return false, nil
+					}
+					return yield(elem)
+				})
+			}), nil
+		},
+	},
+	"mapSeq": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "seq",
+			Type: types.Seq(types.Var("T")),
+		}, {
+			Name: "f",
+			Type: types.Func([]types.Type{types.Var("T")}, types.Var("U")),
+		}},
+		ret: types.Seq(types.Var("U")),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			seq := args[0].(types.Sequence)
+			f := args[1].(types.Function)
+
+			return values.NewSeq(f.Return(), func(ctx context.Context, yield func(any) (bool, error)) error {
+				return seq.Iterate(ctx, func(elem any) (bool, error) {
+					mapped, err := f.Call(ctx, []any{elem})
+					if err != nil {
+						return false, err
+					}
+					return yield(mapped)
+				})
+			}), nil
+		},
+	},
+	"filterSeq": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "seq",
+			Type: types.Seq(types.Var("T")),
+		}, {
+			Name: "condition",
+			Type: types.Func([]types.Type{types.Var("T")}, types.PrimitiveTypeBool),
+		}},
+		ret: types.Seq(types.Var("T")),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			seq := args[0].(types.Sequence)
+			condition := args[1].(types.Function)
+
+			return values.NewSeq(seq.ElementType(), func(ctx context.Context, yield func(any) (bool, error)) error {
+				return seq.Iterate(ctx, func(elem any) (bool, error) {
+					if err := expressions.Step(ctx, nil); err != nil {
+						return false, err
+					}
+
+					ok, err := condition.Call(ctx, []any{elem})
+					if err != nil {
+						return false, err
+					}
+					if ok != true {
+						return true, nil
+					}
+					return yield(elem)
+				})
+			}), nil
+		},
+	},
+	"spawn": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "f",
+			Type: types.Func([]types.Type{}, types.Var("T")),
+		}},
+		ret: types.Future(types.Var("T")),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			// the task runs on its own copy of everything the function can
+			// see, so that it is unaffected by anything built after this
+			f := parser.Snapshot(args[0]).(types.Function)
+
+			return values.Go(f.Return(), func() (ret any, err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("panic in spawned task: %v", r)
+					}
+				}()
+
+				return f.Call(ctx, []any{})
+			}), nil
+		},
+	},
+	"await": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "future",
+			Type: types.Future(types.Var("T")),
+		}},
+		ret: types.Var("T"),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			return args[0].(*values.Future).Await(ctx)
+		},
+	},
+	"send": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "chan",
+			Type: types.Chan(types.Var("T")),
+		}, {
+			Name: "val",
+			Type: types.Var("T"),
+		}},
+		ret: types.PrimitiveTypeUnit,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			if err := args[0].(*values.Chan).Send(ctx, parser.Snapshot(args[1])); err != nil {
+				return nil, err
+			}
+			return struct{}{}, nil
+		},
+	},
+	"recv": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "chan",
+			Type: types.Chan(types.Var("T")),
+		}},
+		ret: types.Var("T"),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			return args[0].(*values.Chan).Recv(ctx)
+		},
+	},
+	"select": &BuiltinFunction{
+		args: []types.Arg{{
+			Name: "chans",
+			Type: types.List(types.Chan(types.Var("T"))),
+		}},
+		ret: types.Object(map[string]types.Type{
+			"index": types.PrimitiveTypeInt,
+			"value": types.Var("T"),
+		}),
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			list := args[0].([]any)
+			if len(list) == 0 {
+				return nil, fmt.Errorf("cannot select from no channels")
+			}
+
+			chans := make([]*values.Chan, 0, len(list))
+			for _, c := range list {
+				chans = append(chans, c.(*values.Chan))
+			}
+
+			i, v, err := values.Select(ctx, chans)
+			if err != nil {
+				return nil, err
+			}
+
+			return map[string]any{
+				"index": i,
+				"value": v,
+			}, nil
+		},
+	},
+}
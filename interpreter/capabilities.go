@@ -0,0 +1,309 @@
+package interpreter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+)
+
+// A Capability names a group of builtins with effects outside the
+// interpreter.  Programs may only use the builtins of capabilities that
+// have been granted to them.
+type Capability string
+
+const (
+	CapabilityIO      Capability = "io"
+	CapabilityFS      Capability = "fs"
+	CapabilityTime    Capability = "time"
+	CapabilityEnv     Capability = "env"
+	CapabilityProcess Capability = "process"
+)
+
+var capabilities = []Capability{
+	CapabilityIO,
+	CapabilityFS,
+	CapabilityTime,
+	CapabilityEnv,
+	CapabilityProcess,
+}
+
+// Grants maps each granted capability to the scopes it is restricted to.
+// A capability granted with no scopes is unrestricted.  Only the fs
+// capability is currently scoped, by directory.
+//
+// Grants implements flag.Value, accepting comma-separated capabilities,
+// each optionally followed by a colon and a scope, e.g. "io,fs:./examples".
+type Grants map[Capability][]string
+
+func (g Grants) String() string {
+	strs := make([]string, 0, len(g))
+	for c, scopes := range g {
+		if len(scopes) == 0 {
+			strs = append(strs, string(c))
+		}
+		for _, scope := range scopes {
+			strs = append(strs, string(c)+":"+scope)
+		}
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ",")
+}
+
+func (g Grants) Set(s string) error {
+	for _, grant := range strings.Split(s, ",") {
+		name, scope, scoped := strings.Cut(strings.TrimSpace(grant), ":")
+		c := Capability(name)
+		if !isCapability(c) {
+			return fmt.Errorf("unknown capability \"%s\"", name)
+		}
+
+		if !scoped {
+			g[c] = []string{}
+			continue
+		}
+
+		if c != CapabilityFS {
+			return fmt.Errorf("capability \"%s\" cannot be scoped", name)
+		}
+		abs, err := filepath.Abs(scope)
+		if err != nil {
+			return fmt.Errorf("invalid scope \"%s\": %w", scope, err)
+		}
+		if scopes, ok := g[c]; ok && len(scopes) == 0 {
+			// already granted without restriction
+			continue
+		}
+		g[c] = append(g[c], abs)
+	}
+
+	return nil
+}
+
+// ParseGrants parses a grant string in the format accepted by Set.
+func ParseGrants(s string) (Grants, error) {
+	g := make(Grants)
+	if s == "" {
+		return g, nil
+	}
+	return g, g.Set(s)
+}
+
+func isCapability(c Capability) bool {
+	for _, known := range capabilities {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (g Grants) allowsPath(path string) bool {
+	scopes, ok := g[CapabilityFS]
+	if !ok {
+		return false
+	}
+	if len(scopes) == 0 {
+		return true
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, scope := range scopes {
+		rel, err := filepath.Rel(scope, abs)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+var capabilityBuiltins = map[Capability]map[string]*BuiltinFunction{
+	CapabilityTime: {
+		"sleep": &BuiltinFunction{
+			args: []types.Arg{{
+				Name: "time",
+				Type: types.PrimitiveTypeInt,
+			}},
+			ret: types.PrimitiveTypeUnit,
+			Fn: func(ctx context.Context, a []any) (any, error) {
+				t := a[0].(int)
+
+				timer := time.NewTimer(time.Duration(t) * time.Millisecond)
+				defer timer.Stop()
+
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-timer.C:
+					return t, nil
+				}
+			},
+		},
+	},
+	CapabilityEnv: {
+		"getEnv": &BuiltinFunction{
+			args: []types.Arg{{
+				Name: "name",
+				Type: types.PrimitiveTypeString,
+			}},
+			ret: types.PrimitiveTypeString,
+			Fn: func(ctx context.Context, args []any) (any, error) {
+				return os.Getenv(args[0].(string)), nil
+			},
+		},
+	},
+}
+
+// init names the builtins, so that profiles and traces can show them
+func init() {
+	for name, f := range builtins {
+		f.(*BuiltinFunction).name = name
+	}
+	nameBuiltins(assertionBuiltins)
+	for _, fs := range capabilityBuiltins {
+		nameBuiltins(fs)
+	}
+}
+
+// nameBuiltins names each builtin after the identifier it is bound to
+func nameBuiltins(fs map[string]*BuiltinFunction) {
+	for name, f := range fs {
+		f.name = name
+	}
+}
+
+// bindBuiltins returns the runtime and type bindings for every builtin.
+// Builtins of capabilities that were not granted are left out of the
+// runtime bindings and given an unavailable type, so that programs using
+// them fail to type check.
+func bindBuiltins(opts Options, dir string, src *sources) (expressions.Bindings, types.TypeBindings) {
+	byCapability := make(map[Capability]map[string]*BuiltinFunction, len(capabilityBuiltins)+3)
+	for c, fs := range capabilityBuiltins {
+		byCapability[c] = fs
+	}
+	byCapability[CapabilityIO] = map[string]*BuiltinFunction{
+		"input": &BuiltinFunction{
+			args: []types.Arg{{
+				Name: "prompt",
+				Type: types.PrimitiveTypeString,
+			}},
+			ret: types.PrimitiveTypeString,
+			Fn: func(ctx context.Context, args []any) (any, error) {
+				fmt.Fprint(opts.stdout(), args[0])
+
+				type line struct {
+					str string
+					err error
+				}
+				read := make(chan line, 1)
+				go func() {
+					reader := bufio.NewReader(opts.stdin())
+					str, err := reader.ReadString('\n')
+					read <- line{str, err}
+				}()
+
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case l := <-read:
+					if l.err != nil {
+						return nil, l.err
+					}
+					if err := expressions.Allocate(ctx, len(l.str)); err != nil {
+						return nil, err
+					}
+					return l.str, nil
+				}
+			},
+		},
+		"print": &BuiltinFunction{
+			args: []types.Arg{{
+				Name: "val",
+				Type: types.PrimitiveTypeAny,
+			}},
+			ret: types.PrimitiveTypeUnit,
+			Fn: func(ctx context.Context, args []any) (any, error) {
+				fmt.Fprintln(opts.stdout(), args[0])
+				return nil, nil
+			},
+		},
+	}
+	byCapability[CapabilityFS] = map[string]*BuiltinFunction{
+		"import": {
+			args: []types.Arg{{
+				Name: "path",
+				Type: types.PrimitiveTypeString,
+			}},
+			ret: types.PrimitiveTypeAny,
+			Fn: func(ctx context.Context, args []any) (any, error) {
+				path := args[0].(string)
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				if !opts.Grants.allowsPath(path) {
+					return nil, fmt.Errorf("path \"%s\" is outside the directories granted to the fs capability", path)
+				}
+				return interpret(ctx, opts, path, src)
+			},
+		},
+	}
+	byCapability[CapabilityProcess] = map[string]*BuiltinFunction{
+		"args": {
+			args: []types.Arg{},
+			ret:  types.List(types.PrimitiveTypeString),
+			Fn: func(ctx context.Context, args []any) (any, error) {
+				ret := make([]any, 0, len(opts.Args))
+				for _, arg := range opts.Args {
+					ret = append(ret, arg)
+				}
+				return ret, nil
+			},
+		},
+	}
+
+	nameBuiltins(byCapability[CapabilityIO])
+	nameBuiltins(byCapability[CapabilityFS])
+	nameBuiltins(byCapability[CapabilityProcess])
+
+	bindings := make(expressions.Bindings)
+	tb := make(types.TypeBindings)
+	for name, f := range builtins {
+		bindings[name] = f
+		tb[name] = builtinType(f.(*BuiltinFunction))
+	}
+	for name, f := range assertionBuiltins {
+		bindings[name] = f
+		tb[name] = builtinType(f)
+	}
+	for c, fs := range byCapability {
+		_, granted := opts.Grants[c]
+		for name, f := range fs {
+			if !granted {
+				tb[name] = types.Unavailable(fmt.Sprintf("builtin \"%s\" requires the \"%s\" capability, which was not granted", name, c))
+				continue
+			}
+			bindings[name] = f
+			tb[name] = builtinType(f)
+		}
+	}
+
+	return bindings, tb
+}
+
+func builtinType(f *BuiltinFunction) types.Type {
+	argTypes := make([]types.Type, 0, len(f.Args()))
+	for _, arg := range f.Args() {
+		argTypes = append(argTypes, arg.Type)
+	}
+	return types.Func(argTypes, f.Return())
+}
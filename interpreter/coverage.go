@@ -0,0 +1,189 @@
+package interpreter
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/brandonksides/grundfunken/parser"
+)
+
+// lineCoverage summarizes the counters of the nodes starting on a line
+type lineCoverage struct {
+	// Count is the greatest number of evaluations of any node on the line
+	Count int64
+	// Missed is whether any node on the line was never evaluated
+	Missed bool
+}
+
+// linesCovered returns the coverage of each line of a file with at least
+// one node on it, keyed by line number from zero
+func linesCovered(file *parser.FileCoverage) map[int]*lineCoverage {
+	ret := make(map[int]*lineCoverage)
+	for _, counter := range file.Counters {
+		line := counter.SourceLocation.LineNumber
+		lc, ok := ret[line]
+		if !ok {
+			lc = &lineCoverage{}
+			ret[line] = lc
+		}
+
+		count := counter.Count()
+		if count > lc.Count {
+			lc.Count = count
+		}
+		if count == 0 {
+			lc.Missed = true
+		}
+	}
+	return ret
+}
+
+func sortedLines(lines map[int]*lineCoverage) []int {
+	ret := make([]int, 0, len(lines))
+	for line := range lines {
+		ret = append(ret, line)
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+// WriteLCOV writes the coverage of every file as a tracefile in the lcov
+// format, with a count for each line with a node on it and for each arm of
+// every "if" and "match" expression.
+func WriteLCOV(w io.Writer, cov *parser.Coverage) error {
+	bw := bufio.NewWriter(w)
+	for _, file := range cov.Files() {
+		fmt.Fprintf(bw, "TN:\nSF:%s\n", file.Path)
+
+		branchesHit := 0
+		branchesFound := 0
+		for block, branch := range file.Branches {
+			reached := false
+			for _, arm := range branch.Arms {
+				if arm.Count() > 0 {
+					reached = true
+				}
+			}
+
+			for i, arm := range branch.Arms {
+				branchesFound++
+				// lcov marks the arms of branches that were never reached
+				// with a dash, rather than a count
+				taken := "-"
+				if reached {
+					taken = fmt.Sprint(arm.Count())
+				}
+				if arm.Count() > 0 {
+					branchesHit++
+				}
+				fmt.Fprintf(bw, "BRDA:%d,%d,%d,%s\n", branch.SourceLocation.LineNumber+1, block, i, taken)
+			}
+		}
+		fmt.Fprintf(bw, "BRF:%d\nBRH:%d\n", branchesFound, branchesHit)
+
+		lines := linesCovered(file)
+		linesHit := 0
+		for _, line := range sortedLines(lines) {
+			if lines[line].Count > 0 {
+				linesHit++
+			}
+			fmt.Fprintf(bw, "DA:%d,%d\n", line+1, lines[line].Count)
+		}
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\nend_of_record\n", len(lines), linesHit)
+	}
+	return bw.Flush()
+}
+
+type htmlCoverageFile struct {
+	Path    string
+	Percent float64
+	Lines   []htmlCoverageLine
+}
+
+type htmlCoverageLine struct {
+	Number int
+	Text   string
+	// Class is "covered", "partial" or "uncovered", or empty for lines
+	// with no nodes on them
+	Class string
+	Count string
+}
+
+// WriteCoverageHTML writes the source of every file as a standalone HTML
+// page, with each line colored by whether its nodes were all, some or none
+// evaluated.
+func WriteCoverageHTML(w io.Writer, cov *parser.Coverage) error {
+	files := make([]htmlCoverageFile, 0)
+	for _, file := range cov.Files() {
+		lines := linesCovered(file)
+
+		hit := 0
+		for _, lc := range lines {
+			if lc.Count > 0 {
+				hit++
+			}
+		}
+		percent := 100.0
+		if len(lines) > 0 {
+			percent = 100 * float64(hit) / float64(len(lines))
+		}
+
+		htmlFile := htmlCoverageFile{
+			Path:    file.Path,
+			Percent: percent,
+			Lines:   make([]htmlCoverageLine, 0, len(file.Lines)),
+		}
+		for i, text := range file.Lines {
+			line := htmlCoverageLine{
+				Number: i + 1,
+				Text:   text,
+			}
+			if lc, ok := lines[i]; ok {
+				line.Count = fmt.Sprint(lc.Count)
+				switch {
+				case lc.Count == 0:
+					line.Class = "uncovered"
+				case lc.Missed:
+					line.Class = "partial"
+				default:
+					line.Class = "covered"
+				}
+			}
+			htmlFile.Lines = append(htmlFile.Lines, line)
+		}
+		files = append(files, htmlFile)
+	}
+
+	return coverageTemplate.Execute(w, files)
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; font-family: monospace; white-space: pre; }
+td { padding: 0 0.5em; }
+td.number, td.count { color: #888; text-align: right; }
+tr.covered td.text { background: #c8f0c8; }
+tr.partial td.text { background: #f0e8a8; }
+tr.uncovered td.text { background: #f0c0c0; }
+</style>
+</head>
+<body>
+<h1>Coverage</h1>
+<ul>
+{{range $i, $file := .}}<li><a href="#file{{$i}}">{{$file.Path}}</a>: {{printf "%.1f" $file.Percent}}% of lines</li>
+{{end}}</ul>
+{{range $i, $file := .}}<h2 id="file{{$i}}">{{$file.Path}}</h2>
+<table>
+{{range $file.Lines}}<tr class="{{.Class}}"><td class="number">{{.Number}}</td><td class="count">{{.Count}}</td><td class="text">{{.Text}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
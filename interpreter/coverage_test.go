@@ -0,0 +1,54 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+	"github.com/brandonksides/grundfunken/parser"
+)
+
+// TestCoverageReportsUnreachableBranches checks that an arm which constant
+// folding would remove is still reported, as never evaluated
+func TestCoverageReportsUnreachableBranches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "unreached.gf")
+	src := "let f = func(x int) int if true then x else x + 1 in f(1)\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cov := parser.NewCoverage()
+	_, _, err := interpreter.Interpret(context.Background(), path, interpreter.Options{
+		Grants:   make(interpreter.Grants),
+		Coverage: cov,
+	})
+	if err != nil {
+		t.Fatalf("failed to run program: %v", err)
+	}
+
+	var got bytes.Buffer
+	if err := interpreter.WriteLCOV(&got, cov); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"TN:",
+		"SF:" + path,
+		"BRDA:1,0,0,1",
+		"BRDA:1,0,1,0",
+		"BRF:2",
+		"BRH:1",
+		"DA:1,1",
+		"LF:1",
+		"LH:1",
+		"end_of_record",
+		"",
+	}, "\n")
+	if got.String() != want {
+		t.Errorf("unexpected lcov report:\n--- want\n%s\n--- got\n%s", want, got.String())
+	}
+}
@@ -0,0 +1,313 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+)
+
+// Debugger pauses the evaluation of a program at breakpoints and after
+// steps, reporting where it stopped, the calls in progress and their
+// bindings.  Evaluation only pauses at stop points: the start of each line
+// and of each function body.  Frontends resume it with Continue, or one
+// of the step methods.
+//
+// While evaluation is paused, any other tasks of the program pause too at
+// their next stop point.
+type Debugger struct {
+	// OnStop, if set, is called whenever evaluation pauses, from the task
+	// that paused.  It may resume evaluation itself, or return and leave
+	// that to another goroutine.
+	OnStop func(*Stopped)
+
+	mu      sync.Mutex
+	resumed *sync.Cond
+	paused  bool
+	mode    stepMode
+	// stepDepth is the depth of the call that was paused in when the
+	// current step began
+	stepDepth int
+	// breakpoints holds the lines of breakpoints, counting from zero, by
+	// file name
+	breakpoints map[string]map[int]bool
+	// positions holds the last stop point reached by each call in
+	// progress, with the nil frame for code outside any call
+	positions map[*expressions.Frame]position
+	// sources holds the files evaluated so far, by file name
+	sources map[string]debugSource
+}
+
+type debugSource struct {
+	path  string
+	lines []string
+}
+
+type stepMode int
+
+const (
+	stepNone stepMode = iota
+	stepEntry
+	stepIn
+	stepOver
+	stepOut
+	stepPause
+)
+
+type position struct {
+	loc      models.SourceLocation
+	bindings expressions.Bindings
+}
+
+// Stopped describes where evaluation has paused
+type Stopped struct {
+	// Reason is why evaluation paused: "entry", "breakpoint", "step" or
+	// "pause"
+	Reason string
+	// Stack holds the calls in progress, innermost first, ending with the
+	// code outside any call
+	Stack []StackFrame
+}
+
+// StackFrame is a call in progress, paused at a stop point
+type StackFrame struct {
+	// Function is the name of the function called, as shown in profiles,
+	// or empty for the code outside any call
+	Function       string
+	SourceLocation models.SourceLocation
+	// Path is the path of the file of the location, or empty for
+	// builtins
+	Path string
+	// Bindings are the identifiers bound where the call is paused,
+	// excluding the builtins, with their values
+	Bindings []Binding
+}
+
+type Binding struct {
+	Name  string
+	Value any
+}
+
+// NewDebugger returns a debugger that pauses at the first stop point if
+// stopOnEntry is set, and otherwise only at breakpoints.
+func NewDebugger(stopOnEntry bool) *Debugger {
+	d := &Debugger{
+		breakpoints: make(map[string]map[int]bool),
+		positions:   make(map[*expressions.Frame]position),
+		sources:     make(map[string]debugSource),
+	}
+	d.resumed = sync.NewCond(&d.mu)
+	if stopOnEntry {
+		d.mode = stepEntry
+	}
+	return d
+}
+
+// SetBreakpoints replaces the breakpoints in the file with the given name
+// with ones at the given lines, counting from one.
+func (d *Debugger) SetBreakpoints(fileName string, lines []int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	set := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		set[line-1] = true
+	}
+	d.breakpoints[fileName] = set
+}
+
+// SourceLine returns the line of source at the given location, or the
+// empty string if it is not in a file evaluated so far
+func (d *Debugger) SourceLine(loc models.SourceLocation) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lines := d.sources[loc.File].lines
+	if loc.LineNumber < 0 || loc.LineNumber >= len(lines) {
+		return ""
+	}
+	return lines[loc.LineNumber]
+}
+
+func (d *Debugger) addSource(fileName string, path string, lines []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sources[fileName] = debugSource{path: path, lines: lines}
+}
+
+// Continue resumes evaluation until the next breakpoint
+func (d *Debugger) Continue() {
+	d.resume(stepNone)
+}
+
+// StepIn resumes evaluation until the next stop point, including those in
+// the functions called
+func (d *Debugger) StepIn() {
+	d.resume(stepIn)
+}
+
+// StepOver resumes evaluation until the next stop point outside the
+// functions called
+func (d *Debugger) StepOver() {
+	d.resume(stepOver)
+}
+
+// StepOut resumes evaluation until the next stop point after the current
+// call returns
+func (d *Debugger) StepOut() {
+	d.resume(stepOut)
+}
+
+// Pause pauses evaluation at the next stop point
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mode = stepPause
+}
+
+func (d *Debugger) resume(mode stepMode) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.paused {
+		return
+	}
+	d.mode = mode
+	d.paused = false
+	d.resumed.Broadcast()
+}
+
+// callHook forgets the position of each call when it returns
+func (d *Debugger) callHook(ctx context.Context, frame *expressions.Frame) func(any, error) {
+	return func(any, error) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.positions, frame)
+	}
+}
+
+// stopHook pauses evaluation at a stop point, if a breakpoint or step
+// calls for it, until it is resumed
+func (d *Debugger) stopHook(ctx context.Context, loc *models.SourceLocation, bindings expressions.Bindings) {
+	d.mu.Lock()
+	// wait for any other task that has paused
+	for d.paused {
+		d.resumed.Wait()
+	}
+
+	frame := expressions.CurrentFrame(ctx)
+	depth := 0
+	if frame != nil {
+		depth = frame.Depth + 1
+	}
+
+	prev, hadPrev := d.positions[frame]
+	d.positions[frame] = position{loc: *loc, bindings: bindings}
+
+	reason := ""
+	switch {
+	case d.mode == stepEntry:
+		reason = "entry"
+	case d.mode == stepIn:
+		reason = "step"
+	case d.mode == stepOver && depth <= d.stepDepth:
+		reason = "step"
+	case d.mode == stepOut && depth < d.stepDepth:
+		reason = "step"
+	case d.mode == stepPause:
+		reason = "pause"
+	case d.breakpoints[loc.File][loc.LineNumber]:
+		// a line may have more than one stop point; only the first of
+		// them reached in a call is a breakpoint
+		if !hadPrev || prev.loc.File != loc.File || prev.loc.LineNumber != loc.LineNumber {
+			reason = "breakpoint"
+		}
+	}
+	if reason == "" {
+		d.mu.Unlock()
+		return
+	}
+
+	d.paused = true
+	d.stepDepth = depth
+	stopped := &Stopped{
+		Reason: reason,
+		Stack:  d.stack(frame),
+	}
+	d.mu.Unlock()
+
+	if d.OnStop != nil {
+		d.OnStop(stopped)
+	}
+
+	d.mu.Lock()
+	for d.paused {
+		d.resumed.Wait()
+	}
+	d.mu.Unlock()
+}
+
+// stack describes the calls in progress from frame outwards
+func (d *Debugger) stack(frame *expressions.Frame) []StackFrame {
+	ret := make([]StackFrame, 0)
+	for f := frame; ; f = f.Parent {
+		stackFrame := StackFrame{}
+		if f != nil {
+			fn := profiledFunction{name: f.Function}
+			if f.SourceLocation != nil {
+				fn.loc = *f.SourceLocation
+			}
+			stackFrame.Function = fn.displayName()
+		}
+
+		pos, ok := d.positions[f]
+		switch {
+		case ok:
+			stackFrame.SourceLocation = pos.loc
+			stackFrame.Bindings = visibleBindings(pos.bindings)
+		case f != nil && f.SourceLocation != nil:
+			// calls of builtins have no stop points, and calls of other
+			// functions are not paused in until their bodies start
+			stackFrame.SourceLocation = *f.SourceLocation
+		case f != nil:
+			// builtins are shown by their name alone
+			stackFrame.SourceLocation = models.SourceLocation{File: "<builtin>"}
+		}
+		stackFrame.Path = d.sources[stackFrame.SourceLocation.File].path
+		ret = append(ret, stackFrame)
+
+		if f == nil {
+			return ret
+		}
+	}
+}
+
+// visibleBindings returns the bindings other than the builtins, ordered by
+// name
+func visibleBindings(bindings expressions.Bindings) []Binding {
+	ret := make([]Binding, 0, len(bindings))
+	for name, value := range bindings {
+		if f, ok := value.(*BuiltinFunction); ok && f.name == name {
+			continue
+		}
+		ret = append(ret, Binding{Name: name, Value: value})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func (sf StackFrame) String() string {
+	name := sf.Function
+	if name == "" {
+		name = "<main>"
+	}
+	if sf.SourceLocation.File == "<builtin>" {
+		return name
+	}
+	return fmt.Sprintf("%s at %s:%d:%d", name, sf.SourceLocation.File, sf.SourceLocation.LineNumber+1, sf.SourceLocation.ColumnNumber+1)
+}
@@ -0,0 +1,166 @@
+package interpreter
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/parser"
+)
+
+// ModuleDoc documents the object exported by a module
+type ModuleDoc struct {
+	File   string
+	Fields []FieldDoc
+}
+
+// FieldDoc documents a field of the object exported by a module
+type FieldDoc struct {
+	parser.FieldDoc
+	Type types.Type
+}
+
+// Document type checks the module in the file at the given path, which
+// must evaluate to an object, and documents each field of the object with
+// its type and doc comments.  The module is never evaluated, so no code in
+// it runs, and every capability is granted so that modules with effects can
+// be documented too.  Like Interpret, it returns the source lines of the
+// file for reporting errors.
+func Document(inputFilePath string) (*ModuleDoc, map[string][]string, error) {
+	src := newSources()
+
+	input, err := os.Open(inputFilePath)
+	if err != nil {
+		return nil, src.lines, fmt.Errorf("failed to open the file at the provided path: %w", err)
+	}
+	defer input.Close()
+
+	fileName := filepath.Base(inputFilePath)
+	expression, err := parse(fileName, input, src)
+	if err != nil {
+		return nil, src.lines, err
+	}
+
+	grants := make(Grants, len(capabilities))
+	for _, c := range capabilities {
+		grants[c] = []string{}
+	}
+	_, builtinTypes := bindBuiltins(Options{Grants: grants}, filepath.Dir(inputFilePath), src)
+
+	t, typeErr := expression.Type(builtinTypes)
+	if typeErr != nil {
+		return nil, src.lines, typeErr
+	}
+
+	objType, ok := t.(types.ObjectType)
+	if !ok {
+		return nil, src.lines, fmt.Errorf("module %s exports %s, rather than an object", fileName, t)
+	}
+
+	docs := parser.ExportedDocs(expression)
+	ret := &ModuleDoc{
+		File:   fileName,
+		Fields: make([]FieldDoc, 0, len(objType.Fields)),
+	}
+	for name, fieldType := range objType.Fields {
+		fieldDoc, ok := docs[name]
+		if !ok {
+			fieldDoc.Name = name
+		}
+		ret.Fields = append(ret.Fields, FieldDoc{
+			FieldDoc: fieldDoc,
+			Type:     fieldType,
+		})
+	}
+	sort.Slice(ret.Fields, func(i, j int) bool {
+		return ret.Fields[i].Name < ret.Fields[j].Name
+	})
+
+	return ret, src.lines, nil
+}
+
+// Signature returns the type of the field, naming the arguments of
+// functions where they are known
+func (fd FieldDoc) Signature() string {
+	funcType, ok := fd.Type.(types.FuncType)
+	if !ok || len(funcType.ArgTypes) != len(fd.Args) {
+		return fd.Type.String()
+	}
+
+	args := make([]string, 0, len(fd.Args))
+	for i, arg := range fd.Args {
+		args = append(args, arg.Name+" "+funcType.ArgTypes[i].String())
+	}
+	return "func(" + strings.Join(args, ", ") + ") " + funcType.ReturnType.String()
+}
+
+// documentedArgs returns the arguments of the field that have doc comments
+func (fd FieldDoc) documentedArgs() []types.Arg {
+	ret := make([]types.Arg, 0)
+	for _, arg := range fd.Args {
+		if arg.Doc != "" {
+			ret = append(ret, arg)
+		}
+	}
+	return ret
+}
+
+// WriteMarkdown writes the documentation as a Markdown document, with a
+// section for each field
+func (md *ModuleDoc) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", md.File)
+	for _, field := range md.Fields {
+		fmt.Fprintf(&sb, "\n## %s\n\n```\n%s\n```\n", field.Name, field.Signature())
+		if field.Doc != "" {
+			fmt.Fprintf(&sb, "\n%s\n", field.Doc)
+		}
+
+		args := field.documentedArgs()
+		if len(args) > 0 {
+			sb.WriteString("\n")
+		}
+		for _, arg := range args {
+			// keep each argument's doc within its list item
+			doc := strings.ReplaceAll(arg.Doc, "\n", "\n  ")
+			fmt.Fprintf(&sb, "- `%s`: %s\n", arg.Name, doc)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteHTML writes the documentation as a standalone HTML page, with a
+// section for each field
+func (md *ModuleDoc) WriteHTML(w io.Writer) error {
+	var sb strings.Builder
+	file := html.EscapeString(md.File)
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n", file, file)
+	for _, field := range md.Fields {
+		name := html.EscapeString(field.Name)
+		fmt.Fprintf(&sb, "<section id=\"%s\">\n<h2>%s</h2>\n<pre><code>%s</code></pre>\n", name, name, html.EscapeString(field.Signature()))
+		if field.Doc != "" {
+			fmt.Fprintf(&sb, "<p>%s</p>\n", html.EscapeString(field.Doc))
+		}
+
+		args := field.documentedArgs()
+		if len(args) > 0 {
+			sb.WriteString("<ul>\n")
+			for _, arg := range args {
+				fmt.Fprintf(&sb, "<li><code>%s</code>: %s</li>\n", html.EscapeString(arg.Name), html.EscapeString(arg.Doc))
+			}
+			sb.WriteString("</ul>\n")
+		}
+		sb.WriteString("</section>\n")
+	}
+	sb.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
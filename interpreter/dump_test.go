@@ -0,0 +1,43 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+)
+
+// TestDumpAST compares the optimized syntax tree dumped for a program to
+// its golden file
+func TestDumpAST(t *testing.T) {
+	const (
+		path       = "testdata/dump_ast.gf"
+		goldenPath = "testdata/dump_ast.gf.golden"
+	)
+
+	var got bytes.Buffer
+	_, _, err := interpreter.Interpret(context.Background(), path, interpreter.Options{
+		Grants:  make(interpreter.Grants),
+		DumpAST: &got,
+	})
+	if err != nil {
+		t.Fatalf("failed to run program: %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile(goldenPath, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file; run the tests with -update to create it: %v", err)
+	}
+	if got.String() != string(want) {
+		t.Errorf("dumped syntax tree differs from %s:\n--- want\n%s\n--- got\n%s", goldenPath, want, got.String())
+	}
+}
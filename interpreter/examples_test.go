@@ -0,0 +1,137 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+	"github.com/brandonksides/grundfunken/parser"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output of the examples")
+
+const (
+	examplesDir = "../examples"
+	goldenDir   = "testdata/examples"
+)
+
+// A backend runs the program in a file, as Interpret does.  Every backend
+// must produce the output recorded in the golden files.
+type backend func(ctx context.Context, inputFilePath string, opts interpreter.Options) (any, map[string][]string, error)
+
+var backends = map[string]backend{
+	"interpreter": interpreter.Interpret,
+	// counting coverage must not change what programs do
+	"coverage": func(ctx context.Context, inputFilePath string, opts interpreter.Options) (any, map[string][]string, error) {
+		opts.Coverage = parser.NewCoverage()
+		return interpreter.Interpret(ctx, inputFilePath, opts)
+	},
+	// and neither must profiling them
+	"profile": func(ctx context.Context, inputFilePath string, opts interpreter.Options) (any, map[string][]string, error) {
+		opts.Profile = interpreter.NewProfiler()
+		return interpreter.Interpret(ctx, inputFilePath, opts)
+	},
+	// nor tracing them
+	"trace": func(ctx context.Context, inputFilePath string, opts interpreter.Options) (any, map[string][]string, error) {
+		opts.Trace = interpreter.NewTracer(io.Discard, nil, nil)
+		return interpreter.Interpret(ctx, inputFilePath, opts)
+	},
+	// nor debugging them, with nothing to pause at
+	"debugger": func(ctx context.Context, inputFilePath string, opts interpreter.Options) (any, map[string][]string, error) {
+		opts.Debugger = interpreter.NewDebugger(false)
+		return interpreter.Interpret(ctx, inputFilePath, opts)
+	},
+}
+
+// TestExamples runs every example program with every backend, comparing
+// the result or error, along with anything printed, to the golden file
+// of the example.
+func TestExamples(t *testing.T) {
+	examples := make([]string, 0)
+	err := filepath.WalkDir(examplesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".gf") {
+			examples = append(examples, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to list examples: %v", err)
+	}
+
+	for _, example := range examples {
+		rel, err := filepath.Rel(examplesDir, example)
+		if err != nil {
+			t.Fatal(err)
+		}
+		goldenPath := filepath.Join(goldenDir, rel+".golden")
+
+		t.Run(filepath.ToSlash(rel), func(t *testing.T) {
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				got := runExample(backends["interpreter"], example)
+				if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("failed to read golden file; run the tests with -update to create it: %v", err)
+			}
+
+			for name, run := range backends {
+				got := runExample(run, example)
+				if got != string(want) {
+					t.Errorf("output of backend %s differs from %s:\n--- want\n%s\n--- got\n%s", name, goldenPath, want, got)
+				}
+			}
+		})
+	}
+}
+
+// runExample runs an example with limits that keep runaway examples from
+// hanging the tests, returning what it printed followed by its result or
+// error, as the command line shows them
+func runExample(run backend, path string) (out string) {
+	var stdout bytes.Buffer
+	defer func() {
+		// record crashes, without the stack trace, rather than failing
+		// every later example too
+		if r := recover(); r != nil {
+			out = stdout.String() + fmt.Sprintf("panic: %v\n", r)
+		}
+	}()
+
+	grants, err := interpreter.ParseGrants("io,time,fs:" + examplesDir)
+	if err != nil {
+		panic(err)
+	}
+	opts := interpreter.Options{
+		Grants:  grants,
+		Timeout: 10 * time.Second,
+		Stdin:   strings.NewReader(""),
+		Stdout:  &stdout,
+	}
+	opts.Budget.MaxSteps = 10_000_000
+
+	result, lines, err := run(context.Background(), path, opts)
+	if err != nil {
+		return stdout.String() + "Error: " + interpreter.FormatError(err, lines)
+	}
+	return stdout.String() + fmt.Sprintf("Result: %v\n", result)
+}
@@ -0,0 +1,143 @@
+package interpreter
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/parser"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+const fuzzFileName = "fuzz.gf"
+
+// addExampleSeeds seeds the corpus of a fuzz target with the source of
+// every example program
+func addExampleSeeds(f *testing.F) {
+	err := filepath.WalkDir("../examples", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".gf") {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f.Add(string(src))
+		return nil
+	})
+	if err != nil {
+		f.Fatalf("failed to read examples: %v", err)
+	}
+}
+
+// FuzzTokenize checks that tokenizing any source either fails with an
+// interpreter error or produces tokens, all with valid locations.
+func FuzzTokenize(f *testing.F) {
+	addExampleSeeds(f)
+	f.Fuzz(func(t *testing.T, src string) {
+		lines := strings.Split(src, "\n")
+		toks, err := tokens.Tokenize(fuzzFileName, lines)
+		if err != nil {
+			checkError(t, err, lines)
+			return
+		}
+
+		for tok, ok := toks.Peek(); ok; tok, ok = toks.Peek() {
+			if locErr := checkLocation(tok.SourceLocation, lines); locErr != nil {
+				t.Fatalf("in token %q: %v", tok.Raw, locErr)
+			}
+			toks.Pop()
+		}
+		if locErr := checkLocation(*toks.CurrentSourceLocation(), lines); locErr != nil {
+			t.Fatalf("at end of input: %v", locErr)
+		}
+	})
+}
+
+// FuzzParse checks that parsing any source either fails with an
+// interpreter error with valid locations or produces an expression.
+func FuzzParse(f *testing.F) {
+	addExampleSeeds(f)
+	f.Fuzz(func(t *testing.T, src string) {
+		sources := newSources()
+		_, err := parse(fuzzFileName, strings.NewReader(src), sources)
+		if err != nil {
+			checkError(t, err, sources.lines[fuzzFileName])
+		}
+	})
+}
+
+// FuzzType checks that type checking, and optimizing, any expression
+// that parses either fails with an interpreter error with valid locations
+// or succeeds.  Nothing is evaluated.
+func FuzzType(f *testing.F) {
+	addExampleSeeds(f)
+	f.Fuzz(func(t *testing.T, src string) {
+		sources := newSources()
+		expression, err := parse(fuzzFileName, strings.NewReader(src), sources)
+		if err != nil {
+			return
+		}
+		lines := sources.lines[fuzzFileName]
+
+		_, builtinTypes := bindBuiltins(Options{}, ".", newSources())
+		_, typeErr := expression.Type(builtinTypes)
+		if typeErr != nil {
+			checkError(t, typeErr, lines)
+			return
+		}
+
+		_ = fmt.Sprint(parser.Optimize(expression))
+	})
+}
+
+// checkError checks that an error is an interpreter error, and that every
+// location in it and its underlying errors is valid
+func checkError(t *testing.T, err error, lines []string) {
+	t.Helper()
+
+	interpreterErr, ok := err.(*models.InterpreterError)
+	if !ok {
+		t.Fatalf("expected an interpreter error, got %T: %v", err, err)
+	}
+
+	for interpreterErr != nil {
+		if interpreterErr.SourceLocation != nil {
+			if locErr := checkLocation(*interpreterErr.SourceLocation, lines); locErr != nil {
+				t.Fatalf("in error %q: %v", interpreterErr.Error(), locErr)
+			}
+		}
+		interpreterErr, _ = interpreterErr.Underlying.(*models.InterpreterError)
+	}
+
+	// reporting the error must not fail either
+	FormatError(err, map[string][]string{fuzzFileName: lines})
+}
+
+// checkLocation checks that a location is within the source, or just
+// after the end of a line
+func checkLocation(loc models.SourceLocation, lines []string) error {
+	if loc.File != fuzzFileName {
+		return fmt.Errorf("location %+v is in an unknown file", loc)
+	}
+	// an empty source has only the location at its start
+	if len(lines) == 0 && loc.LineNumber == 0 && loc.ColumnNumber == 0 {
+		return nil
+	}
+	if loc.LineNumber < 0 || loc.LineNumber >= len(lines) {
+		return fmt.Errorf("location %+v is outside the %d lines of the source", loc, len(lines))
+	}
+	if loc.ColumnNumber < 0 || loc.ColumnNumber > utf8.RuneCountInString(lines[loc.LineNumber]) {
+		return fmt.Errorf("location %+v is outside its line %q", loc, lines[loc.LineNumber])
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package interpreter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/parser"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+type Options struct {
+	// Budget limits the steps and allocations of the evaluation,
+	// including any imported modules
+	Budget expressions.Budget
+	// IntOverflow chooses whether integer arithmetic that overflows an
+	// int fails or continues with arbitrary precision
+	IntOverflow expressions.IntOverflow
+	// Timeout bounds the wall-clock time of the evaluation; zero means
+	// no timeout beyond that of the provided context
+	Timeout time.Duration
+	// Grants lists the capabilities the program may use; builtins of any
+	// other capability fail to type check
+	Grants Grants
+	// Args are exposed to the program through the process capability
+	Args []string
+	// DumpAST, if set, receives the optimized expression of every file
+	// evaluated, just before it is evaluated
+	DumpAST io.Writer
+	// Stdin and Stdout, if set, replace the standard input and output of
+	// the process for the builtins of the io capability
+	Stdin  io.Reader
+	Stdout io.Writer
+	// Coverage, if set, counts the evaluations of every node of every
+	// file evaluated
+	Coverage *parser.Coverage
+	// Profile, if set, records the calls of every function
+	Profile *Profiler
+	// Trace, if set, traces the calls of functions
+	Trace *Tracer
+	// Debugger, if set, may pause evaluation at the stop points of every
+	// file evaluated
+	Debugger *Debugger
+}
+
+func (opts Options) stdin() io.Reader {
+	if opts.Stdin == nil {
+		return os.Stdin
+	}
+	return opts.Stdin
+}
+
+func (opts Options) stdout() io.Writer {
+	if opts.Stdout == nil {
+		return os.Stdout
+	}
+	return opts.Stdout
+}
+
+// Interpret evaluates the program in the file at the given path, or
+// standard input if the path is empty.  Along with the result, it returns
+// the source lines of every file read, keyed by file name, so that errors
+// can be reported with context.
+func Interpret(ctx context.Context, inputFilePath string, opts Options) (any, map[string][]string, error) {
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+
+	src := newSources()
+	ret, err := interpret(ctx, opts, inputFilePath, src)
+	return ret, src.lines, err
+}
+
+// InterpretSource evaluates the program read from source as though it
+// were the contents of a file with the given name.
+func InterpretSource(ctx context.Context, fileName string, source io.Reader, opts Options) (any, map[string][]string, error) {
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+
+	src := newSources()
+	ret, err := evaluate(ctx, opts, filepath.Dir(fileName), fileName, source, src)
+	return ret, src.lines, err
+}
+
+func (opts Options) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = expressions.WithBudget(ctx, opts.Budget)
+	ctx = expressions.WithIntOverflow(ctx, opts.IntOverflow)
+	if opts.Profile != nil {
+		ctx = expressions.WithCallHook(ctx, opts.Profile.hook)
+	}
+	if opts.Trace != nil {
+		ctx = expressions.WithCallHook(ctx, opts.Trace.hook)
+	}
+	if opts.Debugger != nil {
+		ctx = expressions.WithCallHook(ctx, opts.Debugger.callHook)
+		ctx = expressions.WithStopHook(ctx, opts.Debugger.stopHook)
+	}
+	if opts.Timeout > 0 {
+		return context.WithTimeout(ctx, opts.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// sources holds the lines of every file read, which may be added to by
+// imports in concurrently running tasks
+type sources struct {
+	mu    sync.Mutex
+	lines map[string][]string
+}
+
+func newSources() *sources {
+	return &sources{
+		lines: make(map[string][]string),
+	}
+}
+
+func (src *sources) add(fileName string, lines []string) {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	src.lines[fileName] = lines
+}
+
+func (src *sources) get(fileName string) []string {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	return src.lines[fileName]
+}
+
+func interpret(ctx context.Context, opts Options, inputFilePath string, src *sources) (any, error) {
+	var input io.ReadCloser
+
+	// imports are resolved relative to the directory of the importing
+	// file, rather than by changing the working directory, which is shared
+	// by concurrently running tasks
+	var dir, fileName string
+	if inputFilePath == "" {
+		input = os.Stdin
+		dir = "."
+		fileName = "stdin"
+	} else {
+		var err error
+		input, err = os.Open(inputFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the file at the provided path: %w", err)
+		}
+
+		dir = filepath.Dir(inputFilePath)
+		fileName = filepath.Base(inputFilePath)
+	}
+	defer input.Close()
+
+	return evaluate(ctx, opts, dir, fileName, input, src)
+}
+
+func evaluate(ctx context.Context, opts Options, dir string, fileName string, input io.Reader, src *sources) (any, error) {
+	expression, parseErr := parse(fileName, input, src)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	// evaluate the expression to get the final result
+	// with the top-level bindings for certain builtin
+	// identifiers
+	bindings, builtinTypes := bindBuiltins(opts, dir, src)
+
+	_, err := expression.Type(builtinTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// fold constants and drop unreachable branches
+	// now that the whole program is known to be well-typed,
+	// unless coverage is counted, which must report every
+	// branch written, reachable or not
+	if opts.Coverage == nil {
+		expression = parser.Optimize(expression)
+	}
+	if opts.DumpAST != nil {
+		fmt.Fprintf(opts.DumpAST, "%s: %v\n", fileName, expression)
+	}
+	path := filepath.Join(dir, filepath.Base(fileName))
+	if opts.Coverage != nil {
+		expression = opts.Coverage.Instrument(path, src.get(fileName), expression)
+	}
+	if opts.Debugger != nil {
+		opts.Debugger.addSource(fileName, path, src.get(fileName))
+		expression = parser.AddStopPoints(expression)
+	}
+
+	ret, err := expression.Evaluate(ctx, bindings)
+	if err != nil {
+		return ret, err
+	}
+	return ret, nil
+}
+
+// parse reads and parses the whole of a file, adding its lines to src
+func parse(fileName string, input io.Reader, src *sources) (expressions.Expression, error) {
+	// hold all the input mainLines in memory
+	// so we can report errors with context
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	src.add(fileName, lines)
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading input: %w", err)
+	}
+	// split input into "tokens", which are the smallest
+	// meaningful units of the language: words, numbers,
+	// punctuation, etc.
+	toks, err := tokens.Tokenize(fileName, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse the tokens into an "expression", which is a
+	// tree-like structure that represents the semantic
+	// relationships between the tokens
+	expression, err := parser.ParseExpression(toks)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, ok := toks.Peek()
+	if ok {
+		return nil, &models.InterpreterError{
+			Message:        "unexpected token",
+			SourceLocation: &tok.SourceLocation,
+		}
+	}
+
+	return expression, nil
+}
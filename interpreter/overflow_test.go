@@ -0,0 +1,93 @@
+package interpreter_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+	"github.com/brandonksides/grundfunken/models/expressions"
+)
+
+// TestIntOverflow checks that integer arithmetic which overflows an int
+// fails at the operator by default, and continues with arbitrary
+// precision with big ints on
+func TestIntOverflow(t *testing.T) {
+	const minInt = "(-9223372036854775807 - 1)"
+
+	tests := []struct {
+		name    string
+		src     string
+		err     string
+		promote string
+	}{
+		{
+			name:    "add",
+			src:     "9223372036854775807 + 1",
+			err:     "in file overflow.gf at line 1, column 21: integer overflow in 9223372036854775807 + 1",
+			promote: "9223372036854775808",
+		},
+		{
+			name:    "divide",
+			src:     minInt + " / -1",
+			err:     "in file overflow.gf at line 1, column 28: integer overflow in -9223372036854775808 / -1",
+			promote: "9223372036854775808",
+		},
+		{
+			name:    "multiply",
+			src:     minInt + " * -1",
+			err:     "in file overflow.gf at line 1, column 28: integer overflow in -9223372036854775808 * -1",
+			promote: "9223372036854775808",
+		},
+		{
+			name:    "no overflow",
+			src:     minInt + " + 1",
+			promote: "-9223372036854775807",
+		},
+		{
+			name: "compare",
+			src: "let big = 9223372036854775807 + 1 in " +
+				"[big > 9223372036854775807, big * big > big, big - 1 is 9223372036854775807]",
+			err:     "in file overflow.gf at line 1, column 31: integer overflow in 9223372036854775807 + 1",
+			promote: "[true true true]",
+		},
+		{
+			name: "toString and parseInt",
+			src: "let big = 9223372036854775807 * 4 in " +
+				"[toString(big), toString(big * big), parseInt(toString(big)) is big]",
+			err:     "in file overflow.gf at line 1, column 31: integer overflow in 9223372036854775807 * 4",
+			promote: "[36893488147419103228 1361129467683753853558350524547720019984 true]",
+		},
+		{
+			name:    "parseInt",
+			src:     "parseInt(\"99999999999999999999\")",
+			err:     "int parsed from string \"99999999999999999999\" overflows int",
+			promote: "99999999999999999999",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, mode := range []expressions.IntOverflow{expressions.IntOverflowError, expressions.IntOverflowPromote} {
+				want := test.promote
+				if mode == expressions.IntOverflowError && test.err != "" {
+					want = "Error: " + test.err
+				}
+
+				opts := interpreter.Options{
+					Grants:      make(interpreter.Grants),
+					IntOverflow: mode,
+				}
+				result, lines, err := interpreter.InterpretSource(context.Background(), "overflow.gf", strings.NewReader(test.src), opts)
+				got := fmt.Sprint(result)
+				if err != nil {
+					got = "Error: " + strings.SplitN(interpreter.FormatError(err, lines), "\n", 2)[0]
+				}
+				if got != want {
+					t.Errorf("with overflow mode %d: want %s, got %s", mode, want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package interpreter
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+)
+
+// Profiler records the number of calls of every function, and the time
+// spent in each, by the stack of calls it was made from.  It is safe to
+// use from concurrently running tasks.
+type Profiler struct {
+	mu    sync.Mutex
+	start time.Time
+	// functions are the functions called, in order of their first call
+	functions []profiledFunction
+	ids       map[profiledFunction]uint64
+	samples   map[string]*profileSample
+	// childTime holds the time spent in the calls made by each call in
+	// progress
+	childTime sync.Map
+}
+
+// profiledFunction identifies a function by its name and the location of
+// its definition, which is empty for builtins
+type profiledFunction struct {
+	name string
+	loc  models.SourceLocation
+}
+
+type profileSample struct {
+	// stack holds the ids of the functions called, innermost first
+	stack []uint64
+	calls int64
+	// nanos is the time spent in the innermost call, excluding the calls
+	// it made in turn
+	nanos int64
+}
+
+func NewProfiler() *Profiler {
+	return &Profiler{
+		start:   time.Now(),
+		ids:     make(map[profiledFunction]uint64),
+		samples: make(map[string]*profileSample),
+	}
+}
+
+// hook is a call hook that records each call when it returns
+func (p *Profiler) hook(ctx context.Context, frame *expressions.Frame) func(any, error) {
+	start := time.Now()
+	childTime := &atomic.Int64{}
+	p.childTime.Store(frame, childTime)
+
+	return func(any, error) {
+		elapsed := time.Since(start).Nanoseconds()
+		p.childTime.Delete(frame)
+		if frame.Parent != nil {
+			if parentTime, ok := p.childTime.Load(frame.Parent); ok {
+				parentTime.(*atomic.Int64).Add(elapsed)
+			}
+		}
+
+		// calls made concurrently, as by parallel loops, may take longer
+		// in total than the call that made them
+		self := elapsed - childTime.Load()
+		if self < 0 {
+			self = 0
+		}
+		p.record(frame, self)
+	}
+}
+
+func (p *Profiler) record(frame *expressions.Frame, nanos int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stack := make([]uint64, 0, frame.Depth+1)
+	var key strings.Builder
+	for f := frame; f != nil; f = f.Parent {
+		id := p.functionID(f)
+		stack = append(stack, id)
+		fmt.Fprintf(&key, "%d,", id)
+	}
+
+	sample, ok := p.samples[key.String()]
+	if !ok {
+		sample = &profileSample{stack: stack}
+		p.samples[key.String()] = sample
+	}
+	sample.calls++
+	sample.nanos += nanos
+}
+
+func (p *Profiler) functionID(frame *expressions.Frame) uint64 {
+	fn := profiledFunction{name: frame.Function}
+	if frame.SourceLocation != nil {
+		fn.loc = *frame.SourceLocation
+	}
+
+	id, ok := p.ids[fn]
+	if !ok {
+		p.functions = append(p.functions, fn)
+		// ids start from one, since zero means no function
+		id = uint64(len(p.functions))
+		p.ids[fn] = id
+	}
+	return id
+}
+
+// displayName names a function as it is shown in profiles: by the
+// identifier it is bound to, or by where it was defined if it is anonymous
+func (fn profiledFunction) displayName() string {
+	if fn.name == "" {
+		return fmt.Sprintf("func@%s:%d:%d", fn.loc.File, fn.loc.LineNumber+1, fn.loc.ColumnNumber+1)
+	}
+	return fn.name
+}
+
+// line is the line of the definition of a function, counting from one, or
+// zero for builtins
+func (fn profiledFunction) line() int64 {
+	if fn.loc.File == "" {
+		return 0
+	}
+	return int64(fn.loc.LineNumber + 1)
+}
+
+func (fn profiledFunction) fileName() string {
+	if fn.loc.File == "" {
+		return "<builtin>"
+	}
+	return fn.loc.File
+}
+
+// WriteProfile writes the calls recorded so far as a gzipped profile in
+// the protocol buffer format of pprof, with a sample of the number of
+// calls and the time spent for each stack of calls.  Each function is
+// located at the line of its definition.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	strs := newStringTable()
+	var prof protoBuffer
+
+	valueType := func(typ, unit string) []byte {
+		var vt protoBuffer
+		vt.int64Field(1, strs.index(typ))
+		vt.int64Field(2, strs.index(unit))
+		return vt.bytes()
+	}
+	prof.bytesField(1, valueType("calls", "count"))
+	prof.bytesField(1, valueType("time", "nanoseconds"))
+
+	for _, sample := range p.samples {
+		var s protoBuffer
+		s.packedUint64Field(1, sample.stack)
+		s.packedInt64Field(2, []int64{sample.calls, sample.nanos})
+		prof.bytesField(2, s.bytes())
+	}
+
+	// a single mapping holds every function, so that pprof does not look
+	// for the binary they belong to
+	var mapping protoBuffer
+	mapping.uint64Field(1, 1)
+	mapping.int64Field(5, strs.index("grundfunken"))
+	mapping.uint64Field(7, 1)
+	prof.bytesField(3, mapping.bytes())
+
+	// every function has a single location, with the same id
+	for i, fn := range p.functions {
+		id := uint64(i + 1)
+
+		var line protoBuffer
+		line.uint64Field(1, id)
+		line.int64Field(2, fn.line())
+
+		var loc protoBuffer
+		loc.uint64Field(1, id)
+		loc.uint64Field(2, 1)
+		loc.bytesField(4, line.bytes())
+		prof.bytesField(4, loc.bytes())
+
+		var f protoBuffer
+		f.uint64Field(1, id)
+		f.int64Field(2, strs.index(fn.displayName()))
+		f.int64Field(3, strs.index(fn.displayName()))
+		f.int64Field(4, strs.index(fn.fileName()))
+		f.int64Field(5, fn.line())
+		prof.bytesField(5, f.bytes())
+	}
+
+	prof.int64Field(9, p.start.UnixNano())
+	prof.int64Field(10, time.Since(p.start).Nanoseconds())
+	prof.bytesField(11, valueType("calls", "count"))
+	prof.int64Field(12, 1)
+
+	// the string table must be written last, once it holds every string
+	for _, str := range strs.strs {
+		prof.bytesField(6, []byte(str))
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(prof.bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// stringTable holds the strings of a profile, which refers to them by
+// index; the first is always empty
+type stringTable struct {
+	strs    []string
+	indices map[string]int64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{
+		strs:    []string{""},
+		indices: map[string]int64{"": 0},
+	}
+}
+
+func (st *stringTable) index(str string) int64 {
+	i, ok := st.indices[str]
+	if !ok {
+		i = int64(len(st.strs))
+		st.strs = append(st.strs, str)
+		st.indices[str] = i
+	}
+	return i
+}
+
+// protoBuffer encodes a protocol buffer message, one field at a time
+type protoBuffer struct {
+	buf []byte
+}
+
+const (
+	protoWireVarint = 0
+	protoWireBytes  = 2
+)
+
+func (pb *protoBuffer) bytes() []byte {
+	return pb.buf
+}
+
+func (pb *protoBuffer) varint(v uint64) {
+	for v >= 0x80 {
+		pb.buf = append(pb.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	pb.buf = append(pb.buf, byte(v))
+}
+
+func (pb *protoBuffer) tag(field int, wireType int) {
+	pb.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (pb *protoBuffer) uint64Field(field int, v uint64) {
+	pb.tag(field, protoWireVarint)
+	pb.varint(v)
+}
+
+func (pb *protoBuffer) int64Field(field int, v int64) {
+	pb.uint64Field(field, uint64(v))
+}
+
+func (pb *protoBuffer) bytesField(field int, b []byte) {
+	pb.tag(field, protoWireBytes)
+	pb.varint(uint64(len(b)))
+	pb.buf = append(pb.buf, b...)
+}
+
+func (pb *protoBuffer) packedUint64Field(field int, vs []uint64) {
+	var packed protoBuffer
+	for _, v := range vs {
+		packed.varint(v)
+	}
+	pb.bytesField(field, packed.bytes())
+}
+
+func (pb *protoBuffer) packedInt64Field(field int, vs []int64) {
+	var packed protoBuffer
+	for _, v := range vs {
+		packed.varint(uint64(v))
+	}
+	pb.bytesField(field, packed.bytes())
+}
@@ -0,0 +1,60 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonksides/grundfunken/models"
+)
+
+// FormatError formats an error returned by the interpreter for display,
+// innermost cause first, showing the source line of every location it
+// refers to, given the source lines returned along with the error.
+func FormatError(err error, lines map[string][]string) string {
+	var sb strings.Builder
+	formatError(&sb, err, lines)
+	return sb.String()
+}
+
+func formatError(sb *strings.Builder, err error, lines map[string][]string) {
+	interpreterErr, ok := err.(*models.InterpreterError)
+	if !ok {
+		sb.WriteString(err.Error())
+		sb.WriteString("\n\n")
+		return
+	}
+
+	if interpreterErr.Underlying != nil {
+		formatError(sb, interpreterErr.Underlying, lines)
+	}
+
+	if interpreterErr.SourceLocation != nil {
+		sb.WriteString(highlightLocation(lines, interpreterErr.Error(), *interpreterErr.SourceLocation))
+		sb.WriteString("\n")
+	}
+}
+
+func highlightLocation(lines map[string][]string, errStr string, loc models.SourceLocation) string {
+	fileLines, ok := lines[loc.File]
+	// locations at the end of the input, as of errors about missing
+	// tokens, may be just past the last line
+	if !ok || loc.LineNumber < 0 || loc.LineNumber >= len(fileLines) {
+		return errStr
+	}
+
+	line := fileLines[loc.LineNumber]
+
+	return fmt.Sprintf(
+		"in file %s at line %d, column %d: %s\n\n%s\n%s",
+		loc.File,
+		loc.LineNumber+1,
+		loc.ColumnNumber+1,
+		errStr,
+		line,
+		underlineError(loc.ColumnNumber),
+	)
+}
+
+func underlineError(col int) string {
+	return strings.Repeat(" ", col) + "^-here\n"
+}
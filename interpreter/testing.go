@@ -0,0 +1,182 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/models/values"
+)
+
+// TestFileSuffix ends the names of files of tests
+const TestFileSuffix = "_test.gf"
+
+// TestPrefix starts the names of the test functions exported by a test
+// file
+const TestPrefix = "test"
+
+var assertionBuiltins = map[string]*BuiltinFunction{
+	"assert": {
+		args: []types.Arg{{
+			Name: "cond",
+			Type: types.PrimitiveTypeBool,
+		}, {
+			Name: "msg",
+			Type: types.PrimitiveTypeString,
+		}},
+		ret: types.PrimitiveTypeUnit,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			if !args[0].(bool) {
+				return nil, fmt.Errorf("assertion failed: %s", args[1])
+			}
+			return nil, nil
+		},
+	},
+	"assertEq": {
+		args: []types.Arg{{
+			Name: "expected",
+			Type: types.PrimitiveTypeAny,
+		}, {
+			Name: "actual",
+			Type: types.PrimitiveTypeAny,
+		}},
+		ret: types.PrimitiveTypeUnit,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			diff := values.Diff(args[0], args[1])
+			if diff != nil {
+				return nil, fmt.Errorf("values differ:\n  %s", strings.Join(diff, "\n  "))
+			}
+			return nil, nil
+		},
+	},
+	"assertError": {
+		args: []types.Arg{{
+			Name: "f",
+			Type: types.Func([]types.Type{}, types.Var("T")),
+		}},
+		ret: types.PrimitiveTypeUnit,
+		Fn: func(ctx context.Context, args []any) (any, error) {
+			ret, err := args[0].(types.Function).Call(ctx, []any{})
+			if err == nil {
+				return nil, fmt.Errorf("expected an error, got %s", values.Format(ret))
+			}
+			// running out of time is not the error being tested for
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			return nil, nil
+		},
+	},
+}
+
+// TestResult is the outcome of a single test function
+type TestResult struct {
+	File     string
+	Name     string
+	Duration time.Duration
+	// Err is why the test failed, or nil if it passed
+	Err error
+}
+
+// FindTestFiles returns the test files among the given paths, including
+// those anywhere under the directories among them, in lexical order
+func FindTestFiles(paths []string) ([]string, error) {
+	files := make([]string, 0)
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, TestFileSuffix) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// RunTests evaluates the test file at the given path, which must evaluate
+// to an object, and calls each of the functions of no arguments among its
+// fields whose names start with TestPrefix, in lexical order.  A test
+// passes if its function returns without error; the assertion builtins
+// fail with a description of what went wrong.  The options apply to the
+// evaluation of the file and to each test separately, except that the
+// file may always import modules from its own directory.  Like Interpret,
+// it returns the source lines of every file read.
+func RunTests(ctx context.Context, testFilePath string, opts Options) ([]TestResult, map[string][]string, error) {
+	opts.Grants = grantDir(opts.Grants, filepath.Dir(testFilePath))
+
+	src := newSources()
+	fileCtx, cancel := opts.context(ctx)
+	defer cancel()
+
+	exported, err := interpret(fileCtx, opts, testFilePath, src)
+	if err != nil {
+		return nil, src.lines, err
+	}
+
+	obj, ok := exported.(map[string]any)
+	if !ok {
+		return nil, src.lines, fmt.Errorf("test file %s evaluates to %s, rather than an object of tests", testFilePath, values.Format(exported))
+	}
+
+	names := make([]string, 0, len(obj))
+	for name, v := range obj {
+		f, ok := v.(types.Function)
+		if ok && strings.HasPrefix(name, TestPrefix) && len(f.Args()) == 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	results := make([]TestResult, 0, len(names))
+	for _, name := range names {
+		results = append(results, runTest(ctx, opts, testFilePath, name, obj[name].(types.Function)))
+	}
+	return results, src.lines, nil
+}
+
+func runTest(ctx context.Context, opts Options, testFilePath string, name string, f types.Function) TestResult {
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+
+	start := time.Now()
+	_, err := f.Call(ctx, []any{})
+	return TestResult{
+		File:     testFilePath,
+		Name:     name,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+}
+
+// grantDir returns a copy of the grants with the fs capability extended to
+// the given directory
+func grantDir(grants Grants, dir string) Grants {
+	ret := make(Grants, len(grants)+1)
+	for c, scopes := range grants {
+		ret[c] = scopes
+	}
+
+	if scopes, ok := ret[CapabilityFS]; ok && len(scopes) == 0 {
+		// already granted without restriction
+		return ret
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ret
+	}
+	ret[CapabilityFS] = append(append([]string{}, ret[CapabilityFS]...), abs)
+	return ret
+}
@@ -0,0 +1,166 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/values"
+)
+
+// maxTracedValue bounds the length of the values shown in traces, beyond
+// which they are cut short
+const maxTracedValue = 60
+
+// Tracer writes a line for every call of a function, with its arguments,
+// and another when it returns, with its result, each indented by the
+// number of traced calls in progress.  It is safe to use from concurrently
+// running tasks, whose lines are interleaved.
+type Tracer struct {
+	w io.Writer
+	// funcs and files, if not empty, limit tracing to the functions with
+	// the given names, and to those defined or called in files with the
+	// given names
+	funcs map[string]bool
+	files map[string]bool
+
+	mu sync.Mutex
+	// depths holds the number of traced calls in progress in each call in
+	// progress, including itself
+	depths map[*expressions.Frame]int
+}
+
+// NewTracer returns a tracer writing to w, tracing the calls of the named
+// functions, or all functions if none are named, made in the named files,
+// or all files if none are named
+func NewTracer(w io.Writer, funcs []string, files []string) *Tracer {
+	t := &Tracer{
+		w:      w,
+		funcs:  make(map[string]bool, len(funcs)),
+		files:  make(map[string]bool, len(files)),
+		depths: make(map[*expressions.Frame]int),
+	}
+	for _, f := range funcs {
+		t.funcs[f] = true
+	}
+	for _, f := range files {
+		t.files[f] = true
+	}
+	return t
+}
+
+func (t *Tracer) traces(frame *expressions.Frame) bool {
+	if len(t.funcs) > 0 && !t.funcs[frame.Function] {
+		return false
+	}
+	if len(t.files) > 0 {
+		defined := frame.SourceLocation != nil && t.files[frame.SourceLocation.File]
+		called := frame.CallSite != nil && t.files[frame.CallSite.File]
+		if !defined && !called {
+			return false
+		}
+	}
+	return true
+}
+
+// hook is a call hook that traces each call and its return
+func (t *Tracer) hook(ctx context.Context, frame *expressions.Frame) func(any, error) {
+	traced := t.traces(frame)
+
+	t.mu.Lock()
+	depth := 0
+	if frame.Parent != nil {
+		depth = t.depths[frame.Parent]
+	}
+	if traced {
+		depth++
+	}
+	t.depths[frame] = depth
+
+	var name string
+	if traced {
+		name = traceName(frame)
+		args := make([]string, 0, len(frame.Args))
+		for _, arg := range frame.Args {
+			args = append(args, traceValue(arg))
+		}
+		fmt.Fprintf(t.w, "%s%s(%s)%s\n", traceIndent(depth), name, strings.Join(args, ", "), traceLocation(frame))
+	}
+	t.mu.Unlock()
+
+	return func(ret any, err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		delete(t.depths, frame)
+		if !traced {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(t.w, "%s%s failed: %s\n", traceIndent(depth), name, traceError(err))
+			return
+		}
+		fmt.Fprintf(t.w, "%s%s returned %s\n", traceIndent(depth), name, traceValue(ret))
+	}
+}
+
+// traceName names the function of a call as profiles do
+func traceName(frame *expressions.Frame) string {
+	fn := profiledFunction{name: frame.Function}
+	if frame.SourceLocation != nil {
+		fn.loc = *frame.SourceLocation
+	}
+	return fn.displayName()
+}
+
+func traceIndent(depth int) string {
+	return strings.Repeat("  ", depth-1)
+}
+
+// traceLocation describes where a call was made, and where the function
+// called was defined
+func traceLocation(frame *expressions.Frame) string {
+	var sb strings.Builder
+	if frame.CallSite != nil {
+		sb.WriteString(" at " + formatLocation(*frame.CallSite))
+	}
+	if frame.SourceLocation != nil {
+		sb.WriteString(" [defined at " + formatLocation(*frame.SourceLocation) + "]")
+	}
+	return sb.String()
+}
+
+func formatLocation(loc models.SourceLocation) string {
+	return fmt.Sprintf("%s:%d:%d", loc.File, loc.LineNumber+1, loc.ColumnNumber+1)
+}
+
+// traceValue formats a value as the interpreter does, cut short if it is
+// long
+func traceValue(v any) string {
+	return truncate(values.Format(v))
+}
+
+// traceError describes why a call failed by its innermost cause, which is
+// where the failure is reported in full
+func traceError(err error) string {
+	for {
+		interpreterErr, ok := err.(*models.InterpreterError)
+		if !ok || interpreterErr.Underlying == nil {
+			return truncate(err.Error())
+		}
+		err = interpreterErr.Underlying
+	}
+}
+
+func truncate(s string) string {
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if utf8.RuneCountInString(s) <= maxTracedValue {
+		return s
+	}
+	return string([]rune(s)[:maxTracedValue]) + "..."
+}
@@ -0,0 +1,94 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+)
+
+const traceProgram = `let
+    fact = func(n int) int
+        if n < 1 then 1 else n * fact(n - 1),
+    describe = func(l [int]) string
+        "${len(l)} facts",
+    divide = func(a int, b int) int a / b
+in
+    [describe([fact(2)]int), divide(fact(1), fact(0) - 1)]
+`
+
+// TestTrace checks the lines traced for the calls of a small recursive
+// program, which fails in its last call
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		name  string
+		funcs []string
+		files []string
+		want  []string
+	}{
+		{
+			name: "all",
+			want: []string{
+				"fact(2) at trace.gf:8:16 [defined at trace.gf:2:12]",
+				"  fact(1) at trace.gf:3:34 [defined at trace.gf:2:12]",
+				"    fact(0) at trace.gf:3:34 [defined at trace.gf:2:12]",
+				"    fact returned 1",
+				"  fact returned 1",
+				"fact returned 2",
+				"describe([2]) at trace.gf:8:6 [defined at trace.gf:4:16]",
+				"  len([2]) at trace.gf:5:10",
+				"  len returned 1",
+				"describe returned 1 facts",
+				"fact(1) at trace.gf:8:37 [defined at trace.gf:2:12]",
+				"  fact(0) at trace.gf:3:34 [defined at trace.gf:2:12]",
+				"  fact returned 1",
+				"fact returned 1",
+				"fact(0) at trace.gf:8:46 [defined at trace.gf:2:12]",
+				"fact returned 1",
+				"divide(1, 0) at trace.gf:8:30 [defined at trace.gf:6:14]",
+				"divide failed: division by zero",
+			},
+		},
+		{
+			// calls nested in untraced calls are indented only by the
+			// traced calls around them
+			name:  "functions",
+			funcs: []string{"len", "divide"},
+			want: []string{
+				"len([2]) at trace.gf:5:10",
+				"len returned 1",
+				"divide(1, 0) at trace.gf:8:30 [defined at trace.gf:6:14]",
+				"divide failed: division by zero",
+			},
+		},
+		{
+			name:  "other files",
+			files: []string{"other.gf"},
+			want:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got bytes.Buffer
+			opts := interpreter.Options{
+				Grants: make(interpreter.Grants),
+				Trace:  interpreter.NewTracer(&got, test.funcs, test.files),
+			}
+			_, _, err := interpreter.InterpretSource(context.Background(), "trace.gf", strings.NewReader(traceProgram), opts)
+			if err == nil {
+				t.Fatal("expected division by zero")
+			}
+
+			want := strings.Join(test.want, "\n")
+			if len(test.want) > 0 {
+				want += "\n"
+			}
+			if got.String() != want {
+				t.Errorf("unexpected trace:\n--- want\n%s\n--- got\n%s", want, got.String())
+			}
+		})
+	}
+}
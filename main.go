@@ -1,25 +1,47 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"strings"
 
-	"github.com/brandonksides/grundfunken/models"
-	"github.com/brandonksides/grundfunken/models/types"
-	"github.com/brandonksides/grundfunken/parser"
-	"github.com/brandonksides/grundfunken/tokens"
+	"github.com/brandonksides/grundfunken/interpreter"
+	"github.com/brandonksides/grundfunken/models/expressions"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "doc":
+			os.Exit(doc(os.Args[2:]))
+		case "test":
+			os.Exit(test(os.Args[2:]))
+		case "debug":
+			os.Exit(debug(os.Args[2:]))
+		}
+	}
+
 	var inputFilePath string
+	var dumpAST bool
+	opts := interpreter.Options{Grants: make(interpreter.Grants)}
 	flag.StringVar(&inputFilePath, "input", "", "Path to the input file")
+	finishOptions := bindOptionFlags(flag.CommandLine, &opts)
+	flag.BoolVar(&dumpAST, "dump-ast", false, "Print the optimized syntax tree of each file before evaluating it")
+	reports := bindReportFlags(flag.CommandLine)
 	flag.Parse()
+	finishOptions()
+	reports.enable(&opts)
+	if dumpAST {
+		opts.DumpAST = os.Stdout
+	}
+	opts.Args = flag.Args()
 
-	result, lines, err := interpret(inputFilePath)
+	result, lines, err := interpreter.Interpret(context.Background(), inputFilePath, opts)
+	if reportErr := reports.write(opts); reportErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", reportErr)
+	}
 	if err != nil {
 		report(err, lines)
 		return
@@ -28,156 +50,39 @@ func main() {
 	fmt.Printf("Result: %v\n", result)
 }
 
-func interpret(inputFilePath string) (any, map[string][]string, error) {
-	var input io.ReadCloser
-
-	var fileName string
-	if inputFilePath == "" {
-		input = os.Stdin
-		fileName = "stdin"
-	} else {
-		var err error
-		input, err = os.Open(inputFilePath)
-		if err != nil {
-			panic(fmt.Errorf("failed to open the file at the provided path: %w", err))
+// bindOptionFlags registers the flags limiting and configuring evaluation
+// on fs, returning a function that finishes filling in opts once fs has
+// been parsed
+func bindOptionFlags(fs *flag.FlagSet, opts *interpreter.Options) func() {
+	var bigInts, trace bool
+	var traceFuncs, traceFiles string
+	fs.IntVar(&opts.Budget.MaxSteps, "max-steps", 0, "Maximum number of function calls and loop iterations (0 for no limit)")
+	fs.IntVar(&opts.Budget.MaxAllocation, "max-alloc", 0, "Maximum length of any list or string (0 for no limit)")
+	fs.DurationVar(&opts.Timeout, "timeout", 0, "Maximum wall-clock time for evaluation (0 for no limit)")
+	fs.Var(opts.Grants, "allow", "Capabilities to grant, e.g. io,time,fs:./examples (one of io, fs, time, env, process)")
+	fs.BoolVar(&bigInts, "big-ints", false, "Continue integer arithmetic that overflows with arbitrary precision, rather than failing")
+	fs.BoolVar(&trace, "trace", false, "Print each function call, with its arguments and result, to standard error")
+	fs.StringVar(&traceFuncs, "trace-func", "", "Trace only the calls of these functions, e.g. fib,tail (implies -trace)")
+	fs.StringVar(&traceFiles, "trace-file", "", "Trace only the calls of functions defined or called in these files, e.g. utils.gf (implies -trace)")
+
+	return func() {
+		if bigInts {
+			opts.IntOverflow = expressions.IntOverflowPromote
 		}
-
-		splitPath := strings.Split(inputFilePath, "/")
-		oldDir, err := os.Getwd()
-		if err != nil {
-			panic(fmt.Errorf("failed to get the current working directory: %w", err))
+		if trace || traceFuncs != "" || traceFiles != "" {
+			opts.Trace = interpreter.NewTracer(os.Stderr, splitList(traceFuncs), splitList(traceFiles))
 		}
-		os.Chdir(strings.Join(splitPath[:len(splitPath)-1], "/"))
-		defer os.Chdir(oldDir)
-		fileName = splitPath[len(splitPath)-1]
-	}
-	defer input.Close()
-
-	// hold all the input mainLines in memory
-	// so we can report errors with context
-	lines := map[string][]string{fileName: make([]string, 0)}
-	scanner := bufio.NewScanner(input)
-	for scanner.Scan() {
-		lines[fileName] = append(lines[fileName], scanner.Text())
-	}
-
-	if err := scanner.Err(); err != nil {
-		fmt.Printf("error reading input: %v\n", err)
-		return nil, lines, err
-	}
-	// split input into "tokens", which are the smallest
-	// meaningful units of the language: words, numbers,
-	// punctuation, etc.
-	toks, err := tokens.Tokenize(fileName, lines[fileName])
-	if err != nil {
-		return nil, lines, err
-	}
-
-	// parse the tokens into an "expression", which is a
-	// tree-like structure that represents the semantic
-	// relationships between the tokens
-	expression, err := parser.ParseExpression(toks)
-	if err != nil {
-		return nil, lines, err
-	}
-
-	tok, ok := toks.Peek()
-	if ok {
-		return nil, lines, &models.InterpreterError{
-			Message:        "unexpected token",
-			SourceLocation: &tok.SourceLocation,
-		}
-	}
-
-	// evaluate the expression to get the final result
-	// with the top-level bindings for certain builtin
-	// identifiers
-	builtins["import"] = Builtin(
-		[]types.Arg{{
-			Name: "path",
-			Type: types.PrimitiveTypeString,
-		}},
-		types.PrimitiveTypeAny,
-		func(args []any) (any, error) {
-			path := args[0].(string)
-
-			ret, newLines, err := interpret(path)
-			for fileName, fileLines := range newLines {
-				lines[fileName] = fileLines
-			}
-			return ret, err
-		},
-	)
-
-	var builtinTypes = map[string]types.Type{}
-	for name, f := range builtins {
-		f := f.(*BuiltinFunction)
-		argTypes := make([]types.Type, 0, len(f.Args()))
-		for _, arg := range f.Args() {
-			argTypes = append(argTypes, arg.Type)
-		}
-		builtinTypes[name] = types.Func(argTypes, f.Return())
-	}
-
-	_, err = expression.Type(builtinTypes)
-	if err != nil {
-		return nil, lines, err
-	}
-
-	ret, err := expression.Evaluate(builtins)
-	if err != nil {
-		return ret, lines, err
-	}
-	return ret, lines, nil
-}
-
-func report(err error, lines map[string][]string) {
-	fmt.Print("Error: ")
-	reportHelper(err, lines)
-}
-
-func reportHelper(err error, lines map[string][]string) {
-	interpreterErr, ok := err.(*models.InterpreterError)
-	if !ok {
-		fmt.Println(err.Error())
-		fmt.Println()
-		return
-	}
-
-	if interpreterErr.Underlying != nil {
-		reportHelper(interpreterErr.Underlying, lines)
-	}
-
-	if interpreterErr.SourceLocation != nil {
-		highlight, _ := highlightLocation(lines, interpreterErr.Error(), *interpreterErr.SourceLocation)
-		fmt.Println(highlight)
 	}
 }
 
-func highlightLocation(lines map[string][]string, errStr string, loc models.SourceLocation) (string, bool) {
-	fileLines, ok := lines[loc.File]
-	if !ok || loc.LineNumber > len(fileLines) {
-		return errStr, false
+// splitList splits a comma-separated list, which may be empty
+func splitList(s string) []string {
+	if s == "" {
+		return nil
 	}
-
-	line := fileLines[loc.LineNumber]
-
-	return fmt.Sprintf(
-		"in file %s at line %d, column %d: %s\n\n%s\n%s",
-		loc.File,
-		loc.LineNumber+1,
-		loc.ColumnNumber+1,
-		errStr,
-		line,
-		underlineError(loc.ColumnNumber),
-	), true
+	return strings.Split(s, ",")
 }
 
-func underlineError(col int) string {
-	underline := ""
-	for i := 0; i < col; i++ {
-		underline += " "
-	}
-	underline += "^-here\n"
-	return underline
+func report(err error, lines map[string][]string) {
+	fmt.Print("Error: " + interpreter.FormatError(err, lines))
 }
@@ -0,0 +1,75 @@
+package expressions
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/brandonksides/grundfunken/models"
+)
+
+// Budget limits the resources a single evaluation may consume.  A zero
+// value for any field means that resource is unlimited.
+type Budget struct {
+	// MaxSteps bounds the number of function calls and comprehension
+	// iterations performed during evaluation
+	MaxSteps int
+	// MaxAllocation bounds the length of any list or string produced
+	// during evaluation
+	MaxAllocation int
+}
+
+type budgetKey struct{}
+
+type meter struct {
+	budget Budget
+	steps  atomic.Int64
+}
+
+// WithBudget returns a context that meters evaluation against the
+// given budget.  Contexts derived from the result share its meter.
+func WithBudget(ctx context.Context, budget Budget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, &meter{budget: budget})
+}
+
+// Step records a single evaluation step at the given location, failing if
+// the context has been cancelled or the step budget is exhausted.
+func Step(ctx context.Context, loc *models.SourceLocation) *models.InterpreterError {
+	if err := ctx.Err(); err != nil {
+		return &models.InterpreterError{
+			Message:        "evaluation interrupted",
+			Underlying:     err,
+			SourceLocation: loc,
+		}
+	}
+
+	m, ok := ctx.Value(budgetKey{}).(*meter)
+	if !ok || m.budget.MaxSteps <= 0 {
+		return nil
+	}
+
+	if m.steps.Add(1) > int64(m.budget.MaxSteps) {
+		return &models.InterpreterError{
+			Message:        fmt.Sprintf("exceeded budget of %d steps", m.budget.MaxSteps),
+			Underlying:     models.ErrStepLimitExceeded,
+			SourceLocation: loc,
+		}
+	}
+
+	return nil
+}
+
+// Allocate checks that a list or string of the given length may be
+// produced under the context's budget.
+func Allocate(ctx context.Context, size int) error {
+	m, ok := ctx.Value(budgetKey{}).(*meter)
+	if !ok || m.budget.MaxAllocation <= 0 {
+		return nil
+	}
+
+	if size > m.budget.MaxAllocation {
+		return fmt.Errorf("cannot allocate %d elements; limit is %d: %w", size, m.budget.MaxAllocation, models.ErrAllocationLimitExceeded)
+	}
+
+	return nil
+}
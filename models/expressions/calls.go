@@ -0,0 +1,95 @@
+package expressions
+
+import (
+	"context"
+
+	"github.com/brandonksides/grundfunken/models"
+)
+
+// Frame is a call of a function in progress, either of a function
+// defined by the program or of a builtin
+type Frame struct {
+	// Parent is the call in progress that made this one, or nil
+	Parent *Frame
+	// Function is the name of the function called: the identifier it was
+	// bound to, if any
+	Function string
+	// SourceLocation is where the function was defined, or nil for
+	// builtins
+	SourceLocation *models.SourceLocation
+	// CallSite is where the function was called, or nil if it is not
+	// known.  Functions called by builtins are called at the call of the
+	// builtin.
+	CallSite *models.SourceLocation
+	Args     []any
+	// Depth is the number of calls in progress below this one
+	Depth int
+}
+
+// CallHook is notified of every call of a function when it starts,
+// returning a function that is notified when it returns.  Hooks may be
+// called concurrently, by calls in different tasks.
+type CallHook func(ctx context.Context, frame *Frame) func(ret any, err error)
+
+type callHooksKey struct{}
+
+type frameKey struct{}
+
+type callSiteKey struct{}
+
+// WithCallHook returns a context in which every call of a function
+// notifies the hook, after any hooks already in the context.
+func WithCallHook(ctx context.Context, hook CallHook) context.Context {
+	hooks, _ := ctx.Value(callHooksKey{}).([]CallHook)
+	return context.WithValue(ctx, callHooksKey{}, append(hooks[:len(hooks):len(hooks)], hook))
+}
+
+// CurrentFrame returns the innermost call in progress in the context, or
+// nil if there is none.  Calls are only tracked in contexts with hooks.
+func CurrentFrame(ctx context.Context) *Frame {
+	frame, _ := ctx.Value(frameKey{}).(*Frame)
+	return frame
+}
+
+// AtCallSite returns a context in which the next function called is called
+// at the given location.  Call sites are only tracked in contexts with
+// hooks.
+func AtCallSite(ctx context.Context, loc *models.SourceLocation) context.Context {
+	if ctx.Value(callHooksKey{}) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, callSiteKey{}, loc)
+}
+
+// EnterCall records the start of a call of the named function, defined at
+// the given location, notifying the hooks of the context.  It returns the
+// context to evaluate the call in, and a function to call with its result.
+func EnterCall(ctx context.Context, function string, loc *models.SourceLocation, args []any) (context.Context, func(ret any, err error)) {
+	hooks, _ := ctx.Value(callHooksKey{}).([]CallHook)
+	if len(hooks) == 0 {
+		return ctx, func(any, error) {}
+	}
+
+	frame := &Frame{
+		Parent:         CurrentFrame(ctx),
+		Function:       function,
+		SourceLocation: loc,
+		Args:           args,
+	}
+	frame.CallSite, _ = ctx.Value(callSiteKey{}).(*models.SourceLocation)
+	if frame.Parent != nil {
+		frame.Depth = frame.Parent.Depth + 1
+	}
+	ctx = context.WithValue(ctx, frameKey{}, frame)
+
+	exits := make([]func(any, error), 0, len(hooks))
+	for _, hook := range hooks {
+		exits = append(exits, hook(ctx, frame))
+	}
+	return ctx, func(ret any, err error) {
+		// like deferred functions, in the reverse order of the calls
+		for i := len(exits) - 1; i >= 0; i-- {
+			exits[i](ret, err)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package expressions
 
 import (
+	"context"
+
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/types"
 )
@@ -8,7 +10,7 @@ import (
 type Bindings map[string]any
 
 type Expression interface {
-	Evaluate(Bindings) (any, *models.InterpreterError)
+	Evaluate(context.Context, Bindings) (any, *models.InterpreterError)
 	Type(types.TypeBindings) (types.Type, *models.InterpreterError)
 	SourceLocation() *models.SourceLocation
 }
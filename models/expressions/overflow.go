@@ -0,0 +1,28 @@
+package expressions
+
+import "context"
+
+// IntOverflow chooses what happens when integer arithmetic overflows.
+type IntOverflow int
+
+const (
+	// IntOverflowError fails the evaluation at the overflowing operator
+	IntOverflowError IntOverflow = iota
+	// IntOverflowPromote continues with arbitrary-precision integers
+	IntOverflowPromote
+)
+
+type intOverflowKey struct{}
+
+// WithIntOverflow returns a context in which integer arithmetic handles
+// overflow as given.
+func WithIntOverflow(ctx context.Context, mode IntOverflow) context.Context {
+	return context.WithValue(ctx, intOverflowKey{}, mode)
+}
+
+// IntOverflowMode returns how integer overflow is handled in the context;
+// by default, it is an error.
+func IntOverflowMode(ctx context.Context) IntOverflow {
+	mode, _ := ctx.Value(intOverflowKey{}).(IntOverflow)
+	return mode
+}
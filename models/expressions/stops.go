@@ -0,0 +1,29 @@
+package expressions
+
+import (
+	"context"
+
+	"github.com/brandonksides/grundfunken/models"
+)
+
+// StopHook is notified whenever evaluation reaches a stop point of a
+// program instrumented with them, before the expression at the stop point
+// is evaluated with the given bindings.  It may block, to pause the
+// evaluation.
+type StopHook func(ctx context.Context, loc *models.SourceLocation, bindings Bindings)
+
+type stopHookKey struct{}
+
+// WithStopHook returns a context in which reaching a stop point notifies
+// the hook.
+func WithStopHook(ctx context.Context, hook StopHook) context.Context {
+	return context.WithValue(ctx, stopHookKey{}, hook)
+}
+
+// Stop notifies the stop hook of the context, if any, that evaluation has
+// reached a stop point at the given location.
+func Stop(ctx context.Context, loc *models.SourceLocation, bindings Bindings) {
+	if hook, ok := ctx.Value(stopHookKey{}).(StopHook); ok {
+		hook(ctx, loc, bindings)
+	}
+}
@@ -1,5 +1,12 @@
 package models
 
+import "errors"
+
+var (
+	ErrStepLimitExceeded       = errors.New("step limit exceeded")
+	ErrAllocationLimitExceeded = errors.New("allocation limit exceeded")
+)
+
 type InterpreterError struct {
 	Message        string
 	Underlying     error
@@ -15,3 +22,10 @@ type SourceLocation struct {
 func (e *InterpreterError) Error() string {
 	return e.Message
 }
+
+func (e *InterpreterError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Underlying
+}
@@ -0,0 +1,13 @@
+package types
+
+type ChanType struct {
+	ElementType Type
+}
+
+func (ct ChanType) String() string {
+	return "chan " + ct.ElementType.String()
+}
+
+func Chan(elementType Type) ChanType {
+	return ChanType{ElementType: elementType}
+}
@@ -1,12 +1,18 @@
 package types
 
+import "strings"
+
 type FuncType struct {
 	ArgTypes   []Type
 	ReturnType Type
 }
 
 func (ft FuncType) String() string {
-	return "func"
+	args := make([]string, 0, len(ft.ArgTypes))
+	for _, arg := range ft.ArgTypes {
+		args = append(args, arg.String())
+	}
+	return "func(" + strings.Join(args, ", ") + ") " + ft.ReturnType.String()
 }
 
 func Func(argTypes []Type, returnType Type) FuncType {
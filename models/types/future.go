@@ -0,0 +1,13 @@
+package types
+
+type FutureType struct {
+	ElementType Type
+}
+
+func (ft FutureType) String() string {
+	return "future " + ft.ElementType.String()
+}
+
+func Future(elementType Type) FutureType {
+	return FutureType{ElementType: elementType}
+}
@@ -0,0 +1,149 @@
+package types
+
+// VarType is a type variable, standing in the signature of a builtin for
+// whatever type the arguments of a particular call give it.  For example,
+// a builtin of type func([T]) T called on an [int] returns an int.
+type VarType struct {
+	Name string
+}
+
+func (vt VarType) String() string {
+	return vt.Name
+}
+
+func Var(name string) VarType {
+	return VarType{Name: name}
+}
+
+// Instantiate binds the type variables in a function type to the types
+// of the arguments it is called with, returning the function type with
+// every variable replaced by its binding.  Variables that no argument
+// binds are replaced with any.
+func Instantiate(ft FuncType, argTypes []Type) FuncType {
+	vars := make(map[string]Type)
+	for i, arg := range ft.ArgTypes {
+		if i < len(argTypes) {
+			bindVars(arg, argTypes[i], vars)
+		}
+	}
+
+	return substitute(ft, vars).(FuncType)
+}
+
+// HasVars reports whether a type contains any type variables.
+func HasVars(t Type) bool {
+	switch t := t.(type) {
+	case VarType:
+		return true
+	case ListType:
+		return HasVars(t.ElementType)
+	case SeqType:
+		return HasVars(t.ElementType)
+	case ChanType:
+		return HasVars(t.ElementType)
+	case FutureType:
+		return HasVars(t.ElementType)
+	case ObjectType:
+		for _, field := range t.Fields {
+			if HasVars(field) {
+				return true
+			}
+		}
+	case FuncType:
+		for _, arg := range t.ArgTypes {
+			if HasVars(arg) {
+				return true
+			}
+		}
+		return HasVars(t.ReturnType)
+	case sumType:
+		for _, addend := range t.Types {
+			if HasVars(addend) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func bindVars(param, arg Type, vars map[string]Type) {
+	switch param := param.(type) {
+	case VarType:
+		if bound, ok := vars[param.Name]; ok {
+			if sum := Sum(bound, arg); sum != nil {
+				vars[param.Name] = sum
+			}
+		} else {
+			vars[param.Name] = arg
+		}
+	case ListType:
+		if argList, ok := arg.(ListType); ok {
+			bindVars(param.ElementType, argList.ElementType, vars)
+		}
+	case SeqType:
+		if argSeq, ok := arg.(SeqType); ok {
+			bindVars(param.ElementType, argSeq.ElementType, vars)
+		}
+	case ChanType:
+		if argChan, ok := arg.(ChanType); ok {
+			bindVars(param.ElementType, argChan.ElementType, vars)
+		}
+	case FutureType:
+		if argFuture, ok := arg.(FutureType); ok {
+			bindVars(param.ElementType, argFuture.ElementType, vars)
+		}
+	case ObjectType:
+		if argObj, ok := arg.(ObjectType); ok {
+			for k, field := range param.Fields {
+				if argField, ok := argObj.Fields[k]; ok {
+					bindVars(field, argField, vars)
+				}
+			}
+		}
+	case FuncType:
+		if argFunc, ok := arg.(FuncType); ok && len(argFunc.ArgTypes) == len(param.ArgTypes) {
+			for i, paramArg := range param.ArgTypes {
+				bindVars(paramArg, argFunc.ArgTypes[i], vars)
+			}
+			bindVars(param.ReturnType, argFunc.ReturnType, vars)
+		}
+	}
+}
+
+func substitute(t Type, vars map[string]Type) Type {
+	switch t := t.(type) {
+	case VarType:
+		if bound, ok := vars[t.Name]; ok {
+			return bound
+		}
+		return PrimitiveTypeAny
+	case ListType:
+		return List(substitute(t.ElementType, vars))
+	case SeqType:
+		return Seq(substitute(t.ElementType, vars))
+	case ChanType:
+		return Chan(substitute(t.ElementType, vars))
+	case FutureType:
+		return Future(substitute(t.ElementType, vars))
+	case ObjectType:
+		fields := make(map[string]Type, len(t.Fields))
+		for k, field := range t.Fields {
+			fields[k] = substitute(field, vars)
+		}
+		return Object(fields)
+	case FuncType:
+		args := make([]Type, 0, len(t.ArgTypes))
+		for _, arg := range t.ArgTypes {
+			args = append(args, substitute(arg, vars))
+		}
+		return Func(args, substitute(t.ReturnType, vars))
+	case sumType:
+		addends := make([]Type, 0, len(t.Types))
+		for _, addend := range t.Types {
+			addends = append(addends, substitute(addend, vars))
+		}
+		return Sum(addends...)
+	default:
+		return t
+	}
+}
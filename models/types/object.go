@@ -1,19 +1,24 @@
 package types
 
+import "sort"
+
 type ObjectType struct {
 	Fields map[string]Type
 }
 
 func (ot ObjectType) String() string {
+	keys := make([]string, 0, len(ot.Fields))
+	for k := range ot.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	str := "{"
-	first := true
-	for k, ty := range ot.Fields {
-		if first {
-			first = false
-		} else {
+	for i, k := range keys {
+		if i > 0 {
 			str += ", "
 		}
-		str += k + ": " + ty.String()
+		str += k + ": " + ot.Fields[k].String()
 	}
 	str += "}"
 	return str
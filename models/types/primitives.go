@@ -8,6 +8,7 @@ const (
 	PrimitiveTypeBool
 	PrimitiveTypeUnit
 	PrimitiveTypeAny
+	PrimitiveTypeFloat
 )
 
 func (t PrimitiveType) String() string {
@@ -22,6 +23,8 @@ func (t PrimitiveType) String() string {
 		return "any"
 	case PrimitiveTypeUnit:
 		return "unit"
+	case PrimitiveTypeFloat:
+		return "float"
 	default:
 		return "unknown"
 	}
@@ -39,6 +42,8 @@ func ParsePrimitive(s string) PrimitiveType {
 		return PrimitiveTypeUnit
 	case "any":
 		return PrimitiveTypeAny
+	case "float":
+		return PrimitiveTypeFloat
 	default:
 		return PrimitiveTypeAny
 	}
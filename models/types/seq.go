@@ -0,0 +1,24 @@
+package types
+
+import "context"
+
+type SeqType struct {
+	ElementType Type
+}
+
+func (st SeqType) String() string {
+	return "seq " + st.ElementType.String()
+}
+
+func Seq(elementType Type) SeqType {
+	return SeqType{ElementType: elementType}
+}
+
+// Sequence is the runtime value of a lazy sequence.  Its elements are only
+// computed as they are iterated over, so a sequence may be infinite.
+type Sequence interface {
+	// Iterate calls yield with each element of the sequence in order,
+	// stopping early if yield returns false or an error
+	Iterate(ctx context.Context, yield func(any) (bool, error)) error
+	ElementType() Type
+}
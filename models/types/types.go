@@ -1,7 +1,9 @@
 package types
 
 import (
+	"context"
 	"fmt"
+	"math/big"
 )
 
 type TypeBindings map[string]Type
@@ -14,8 +16,10 @@ func TypeOf(v interface{}) (Type, error) {
 	switch v := v.(type) {
 	case nil:
 		return PrimitiveTypeUnit, nil
-	case int:
+	case int, *big.Int:
 		return PrimitiveTypeInt, nil
+	case float64:
+		return PrimitiveTypeFloat, nil
 	case string:
 		return PrimitiveTypeString, nil
 	case bool:
@@ -40,6 +44,8 @@ func TypeOf(v interface{}) (Type, error) {
 			}
 		}
 		return Object(fieldTypes), nil
+	case Typed:
+		return v.Type(), nil
 	case Function:
 		typs := make([]Type, 0)
 		for _, arg := range v.Args() {
@@ -51,8 +57,15 @@ func TypeOf(v interface{}) (Type, error) {
 	}
 }
 
+// Typed is implemented by runtime values that know their own type, such
+// as sequences and channels, whose types can't be recovered from their
+// contents.
+type Typed interface {
+	Type() Type
+}
+
 type Function interface {
-	Call([]any) (any, error)
+	Call(context.Context, []any) (any, error)
 	Args() []Arg
 	Return() Type
 }
@@ -60,6 +73,12 @@ type Function interface {
 type Arg struct {
 	Name string
 	Type Type
+	// Doc is the text of the doc comment on the argument, if any
+	Doc string
+}
+
+func (a Arg) String() string {
+	return a.Name + " " + a.Type.String()
 }
 
 func IsSuperTo(t1, t2 Type) (bool, error) {
@@ -101,6 +120,31 @@ func IsSuperTo(t1, t2 Type) (bool, error) {
 			return IsSuperTo(t1.ElementType, t2List.ElementType)
 		}
 		return false, nil
+	case SeqType:
+		if t2Seq, ok := t2.(SeqType); ok {
+			return IsSuperTo(t1.ElementType, t2Seq.ElementType)
+		}
+		return false, nil
+	case ChanType:
+		// channels are both written and read, so their element
+		// types must match exactly
+		if t2Chan, ok := t2.(ChanType); ok {
+			super, err := IsSuperTo(t1.ElementType, t2Chan.ElementType)
+			if err != nil || !super {
+				return false, err
+			}
+			return IsSuperTo(t2Chan.ElementType, t1.ElementType)
+		}
+		return false, nil
+	case FutureType:
+		if t2Future, ok := t2.(FutureType); ok {
+			return IsSuperTo(t1.ElementType, t2Future.ElementType)
+		}
+		return false, nil
+	case VarType:
+		// a variable left in a function type that was never
+		// instantiated could stand for any type
+		return true, nil
 	case ObjectType:
 		if t2Obj, ok := t2.(ObjectType); ok {
 			for k, v1 := range t1.Fields {
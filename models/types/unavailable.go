@@ -0,0 +1,16 @@
+package types
+
+// UnavailableType is the type of an identifier that is bound but may not be
+// used, such as a builtin whose capability was not granted.  Reason explains
+// why to anyone who tries.
+type UnavailableType struct {
+	Reason string
+}
+
+func (ut UnavailableType) String() string {
+	return "unavailable"
+}
+
+func Unavailable(reason string) UnavailableType {
+	return UnavailableType{Reason: reason}
+}
@@ -0,0 +1,105 @@
+package values
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Integers too large for an int are represented by *big.Int, which only
+// ever holds values outside the range of an int.  Keeping each integer in
+// exactly one representation means equal integers are always represented
+// alike.
+
+// BigInt returns the integer v, which is an int or *big.Int, as a
+// *big.Int that is safe to modify.
+func BigInt(v any) *big.Int {
+	switch v := v.(type) {
+	case int:
+		return big.NewInt(int64(v))
+	case *big.Int:
+		return new(big.Int).Set(v)
+	default:
+		return nil
+	}
+}
+
+// NormalizeInt returns n as an int if it fits in one.
+func NormalizeInt(n *big.Int) any {
+	if n.IsInt64() {
+		return int(n.Int64())
+	}
+	return n
+}
+
+// IsInt reports whether v is an integer, of either representation.
+func IsInt(v any) bool {
+	switch v.(type) {
+	case int, *big.Int:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseInt parses an integer, with an optional sign, in the given base.  If
+// the base is zero, it is taken from the prefix of the digits, as in a
+// literal: "0x" for hexadecimal, "0b" for binary, "0o" for octal, and none
+// for decimal.
+func ParseInt(s string, base int) (any, error) {
+	digits := s
+	neg := false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		neg = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	if base == 0 {
+		base = 10
+		if len(digits) >= 2 && digits[0] == '0' {
+			switch digits[1] {
+			case 'x', 'X':
+				base, digits = 16, digits[2:]
+			case 'b', 'B':
+				base, digits = 2, digits[2:]
+			case 'o', 'O':
+				base, digits = 8, digits[2:]
+			}
+		}
+	}
+
+	if base < 2 || base > 36 {
+		return nil, fmt.Errorf("invalid base %d", base)
+	}
+
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok || strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		return nil, fmt.Errorf("invalid base %d integer \"%s\"", base, s)
+	}
+	if neg {
+		n.Neg(n)
+	}
+
+	return NormalizeInt(n), nil
+}
+
+// FormatInt formats an integer in the given base, with the prefix used for
+// that base in literals.
+func FormatInt(v any, base int) string {
+	n := BigInt(v)
+
+	prefix := ""
+	switch base {
+	case 16:
+		prefix = "0x"
+	case 2:
+		prefix = "0b"
+	case 8:
+		prefix = "0o"
+	}
+
+	if n.Sign() < 0 {
+		return "-" + prefix + n.Neg(n).Text(base)
+	}
+	return prefix + n.Text(base)
+}
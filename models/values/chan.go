@@ -0,0 +1,88 @@
+package values
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/brandonksides/grundfunken/models/types"
+)
+
+// Chan is a channel over which concurrent tasks pass values of its
+// element type.
+type Chan struct {
+	elemType types.Type
+	ch       chan any
+}
+
+var _ types.Typed = &Chan{}
+
+func NewChan(elemType types.Type, size int) *Chan {
+	return &Chan{
+		elemType: elemType,
+		ch:       make(chan any, size),
+	}
+}
+
+// Send blocks until the value is received, or buffered if the channel has
+// room, or the context is done.
+func (c *Chan) Send(ctx context.Context, v any) error {
+	t, err := types.TypeOf(v)
+	if err != nil {
+		return err
+	}
+	ok, err := types.IsSuperTo(c.elemType, t)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("cannot send %s on %s", t, c.Type())
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c.ch <- v:
+		return nil
+	}
+}
+
+// Recv blocks until a value is available or the context is done.
+func (c *Chan) Recv(ctx context.Context) (any, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case v := <-c.ch:
+		return v, nil
+	}
+}
+
+// Select blocks until a value is available on any of the given channels,
+// returning the index of that channel and the value received from it.
+func Select(ctx context.Context, chans []*Chan) (int, any, error) {
+	cases := make([]reflect.SelectCase, 0, len(chans)+1)
+	cases = append(cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	})
+	for _, c := range chans {
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(c.ch),
+		})
+	}
+
+	chosen, v, _ := reflect.Select(cases)
+	if chosen == 0 {
+		return 0, nil, ctx.Err()
+	}
+	return chosen - 1, v.Interface(), nil
+}
+
+func (c *Chan) Type() types.Type {
+	return types.Chan(c.elemType)
+}
+
+func (c *Chan) String() string {
+	return "<" + c.Type().String() + ">"
+}
@@ -0,0 +1,94 @@
+package values
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diff describes how the actual value differs from the expected one, with
+// a line for each differing element of a list or field of an object,
+// prefixed with its path from the outermost value.  It returns nil if the
+// values are structurally equal.
+func Diff(expected, actual any) []string {
+	return diff("", expected, actual)
+}
+
+func diff(path string, expected, actual any) []string {
+	switch e := expected.(type) {
+	case []any:
+		a, ok := actual.([]any)
+		if !ok {
+			break
+		}
+
+		ret := make([]string, 0)
+		for i := 0; i < len(e) || i < len(a); i++ {
+			elemPath := path + "[" + strconv.Itoa(i) + "]"
+			if i >= len(a) {
+				ret = append(ret, fmt.Sprintf("%s: expected %s, got nothing", elemPath, formatQuoted(e[i])))
+			} else if i >= len(e) {
+				ret = append(ret, fmt.Sprintf("%s: expected nothing, got %s", elemPath, formatQuoted(a[i])))
+			} else {
+				ret = append(ret, diff(elemPath, e[i], a[i])...)
+			}
+		}
+		return nilIfEmpty(ret)
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		ret := make([]string, 0)
+		for _, k := range keys {
+			fieldPath := path + "." + k
+			eField, inExpected := e[k]
+			aField, inActual := a[k]
+			if !inActual {
+				ret = append(ret, fmt.Sprintf("%s: expected %s, got nothing", fieldPath, formatQuoted(eField)))
+			} else if !inExpected {
+				ret = append(ret, fmt.Sprintf("%s: expected nothing, got %s", fieldPath, formatQuoted(aField)))
+			} else {
+				ret = append(ret, diff(fieldPath, eField, aField)...)
+			}
+		}
+		return nilIfEmpty(ret)
+	}
+
+	if Key(expected) == Key(actual) {
+		return nil
+	}
+
+	if path == "" {
+		return []string{fmt.Sprintf("expected %s, got %s", formatQuoted(expected), formatQuoted(actual))}
+	}
+	return []string{fmt.Sprintf("%s: expected %s, got %s", path, formatQuoted(expected), formatQuoted(actual))}
+}
+
+func nilIfEmpty(lines []string) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+	return lines
+}
+
+// formatQuoted formats a value as Format does, but quotes strings even
+// when they are not nested, so that they are not mistaken for other values
+func formatQuoted(v any) string {
+	var sb strings.Builder
+	writeFormatted(&sb, v)
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package values
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format formats a value for display, as toString and string
+// interpolation do.  Strings are formatted as they are, unless they are
+// inside a list or object, where they are quoted.
+func Format(v any) string {
+	if str, ok := v.(string); ok {
+		return str
+	}
+
+	var sb strings.Builder
+	writeFormatted(&sb, v)
+	return sb.String()
+}
+
+func writeFormatted(sb *strings.Builder, v any) {
+	switch v := v.(type) {
+	case nil, struct{}:
+		sb.WriteString("unit")
+	case int:
+		sb.WriteString(strconv.Itoa(v))
+	case *big.Int:
+		sb.WriteString(v.String())
+	case float64:
+		sb.WriteString(FormatFloat(v))
+	case bool:
+		sb.WriteString(strconv.FormatBool(v))
+	case string:
+		sb.WriteString(strconv.Quote(v))
+	case []any:
+		sb.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeFormatted(sb, elem)
+		}
+		sb.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		sb.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(k)
+			sb.WriteString(": ")
+			writeFormatted(sb, v[k])
+		}
+		sb.WriteByte('}')
+	default:
+		fmt.Fprint(sb, v)
+	}
+}
@@ -0,0 +1,50 @@
+package values
+
+import (
+	"context"
+
+	"github.com/brandonksides/grundfunken/models/types"
+)
+
+// Future is the eventual result of a task running concurrently.
+type Future struct {
+	elemType types.Type
+	done     chan struct{}
+	val      any
+	err      error
+}
+
+var _ types.Typed = &Future{}
+
+// Go runs f in a new goroutine, returning a future for its result.
+func Go(elemType types.Type, f func() (any, error)) *Future {
+	fut := &Future{
+		elemType: elemType,
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(fut.done)
+		fut.val, fut.err = f()
+	}()
+
+	return fut
+}
+
+// Await blocks until the task has finished or the context is done.
+func (f *Future) Await(ctx context.Context) (any, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-f.done:
+		return f.val, f.err
+	}
+}
+
+func (f *Future) Type() types.Type {
+	return types.Future(f.elemType)
+}
+
+func (f *Future) String() string {
+	return "<" + f.Type().String() + ">"
+}
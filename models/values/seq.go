@@ -0,0 +1,58 @@
+package values
+
+import (
+	"context"
+
+	"github.com/brandonksides/grundfunken/models/types"
+)
+
+// Seq is a lazy sequence whose elements are produced by a function each
+// time it is iterated over.
+type Seq struct {
+	elemType types.Type
+	iterate  func(ctx context.Context, yield func(any) (bool, error)) error
+}
+
+var (
+	_ types.Sequence = &Seq{}
+	_ types.Typed    = &Seq{}
+)
+
+func NewSeq(elemType types.Type, iterate func(ctx context.Context, yield func(any) (bool, error)) error) *Seq {
+	if elemType == nil {
+		elemType = types.PrimitiveTypeAny
+	}
+	return &Seq{
+		elemType: elemType,
+		iterate:  iterate,
+	}
+}
+
+// SeqOf returns a sequence of the elements of a list.
+func SeqOf(elemType types.Type, list []any) *Seq {
+	return NewSeq(elemType, func(ctx context.Context, yield func(any) (bool, error)) error {
+		for _, elem := range list {
+			more, err := yield(elem)
+			if err != nil || !more {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Seq) Iterate(ctx context.Context, yield func(any) (bool, error)) error {
+	return s.iterate(ctx, yield)
+}
+
+func (s *Seq) ElementType() types.Type {
+	return s.elemType
+}
+
+func (s *Seq) Type() types.Type {
+	return types.Seq(s.elemType)
+}
+
+func (s *Seq) String() string {
+	return "<" + types.Seq(s.elemType).String() + ">"
+}
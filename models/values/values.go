@@ -0,0 +1,75 @@
+package values
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Key returns a string identifying the structure of a value: two values
+// have the same key exactly when they are structurally equal.  Lists and
+// objects are compared element by element, and functions by identity.
+func Key(v any) string {
+	var sb strings.Builder
+	writeKey(&sb, v)
+	return sb.String()
+}
+
+func writeKey(sb *strings.Builder, v any) {
+	switch v := v.(type) {
+	case nil:
+		sb.WriteString("unit")
+	case struct{}:
+		sb.WriteString("unit")
+	case int:
+		sb.WriteString(strconv.Itoa(v))
+	case *big.Int:
+		sb.WriteString(v.String())
+	case float64:
+		sb.WriteString(FormatFloat(v))
+	case bool:
+		sb.WriteString(strconv.FormatBool(v))
+	case string:
+		sb.WriteString(strconv.Quote(v))
+	case []any:
+		sb.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			writeKey(sb, elem)
+		}
+		sb.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		sb.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(strconv.Quote(k))
+			sb.WriteByte(':')
+			writeKey(sb, v[k])
+		}
+		sb.WriteByte('}')
+	default:
+		fmt.Fprintf(sb, "%T@%p", v, v)
+	}
+}
+
+// FormatFloat formats a float in as few digits as identify it, always with
+// a decimal point or exponent, so that floats are never mistaken for ints.
+func FormatFloat(f float64) string {
+	str := strconv.FormatFloat(f, 'g', -1, 64)
+	if strings.ContainsAny(str, ".eIN") {
+		return str
+	}
+	return str + ".0"
+}
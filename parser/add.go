@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
@@ -21,64 +22,44 @@ func (ae *AddExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 		return nil, err
 	}
 
-	if firstType != types.PrimitiveTypeInt {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to type %s", ae.op.Value, firstType),
-			SourceLocation: &ae.op.SourceLocation,
-		}
-	}
-
 	secondType, err := ae.second.Type(tb)
 	if err != nil {
 		return nil, err
 	}
 
-	if secondType != types.PrimitiveTypeInt {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to type %s", ae.op.Value, secondType),
-			SourceLocation: &ae.op.SourceLocation,
-		}
-	}
-
-	return types.PrimitiveTypeInt, nil
+	return numericType(ae.op.Value, ae.first, ae.second, firstType, secondType)
 }
 
-func (ae *AddExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	v1, err := ae.first.Evaluate(bindings)
+func (ae *AddExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	v1, err := ae.first.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
-	v1Adder, ok := v1.(int)
-	if !ok {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to first operand %v", ae.op.Value, v1),
-			SourceLocation: ae.first.SourceLocation(),
-		}
-	}
 
-	v2, err := ae.second.Evaluate(bindings)
+	v2, err := ae.second.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
-	v2Adder, ok := v2.(int)
-	if !ok {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to second operand %v", ae.op.Value, v2),
-			SourceLocation: ae.second.SourceLocation(),
-		}
+
+	isFloat, err := numericOperands(ae.op.Value, ae.first, ae.second, v1, v2)
+	if err != nil {
+		return nil, err
 	}
 
-	switch ae.op.Type {
-	case tokens.PLUS:
-		return v1Adder + v2Adder, nil
-	case tokens.MINUS:
-		return v1Adder - v2Adder, nil
-	default:
+	if isFloat {
+		switch ae.op.Type {
+		case tokens.PLUS:
+			return v1.(float64) + v2.(float64), nil
+		case tokens.MINUS:
+			return v1.(float64) - v2.(float64), nil
+		}
 		return nil, &models.InterpreterError{
 			Message:        "invalid operator",
 			SourceLocation: &ae.op.SourceLocation,
 		}
 	}
+
+	return intArithmetic(ctx, ae.op, v1, v2)
 }
 
 func (ae *AddExpression) SourceLocation() *models.SourceLocation {
@@ -116,3 +97,7 @@ func foldAdd(first expressions.Expression, toks *tokens.TokenStack) (exp express
 
 	return foldAdd(withNext, toks)
 }
+
+func (ae *AddExpression) String() string {
+	return fmt.Sprintf("(%v %s %v)", ae.first, ae.op.Value, ae.second)
+}
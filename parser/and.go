@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
@@ -42,8 +43,8 @@ func (ae *AndExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 	return types.PrimitiveTypeBool, nil
 }
 
-func (ae *AndExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	v1, err := ae.Left.Evaluate(bindings)
+func (ae *AndExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	v1, err := ae.Left.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +60,7 @@ func (ae *AndExpression) Evaluate(bindings expressions.Bindings) (any, *models.I
 		return false, nil
 	}
 
-	v2, err := ae.Right.Evaluate(bindings)
+	v2, err := ae.Right.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -104,3 +105,7 @@ func foldAnd(first expressions.Expression, toks *tokens.TokenStack) (exp express
 		Right: right,
 	}, nil
 }
+
+func (ae *AndExpression) String() string {
+	return fmt.Sprintf("(%v and %v)", ae.Left, ae.Right)
+}
@@ -8,30 +8,37 @@ import (
 
 func parseExpressions(toks *tokens.TokenStack) (exps []expressions.Expression, err *models.InterpreterError) {
 	exps = make([]expressions.Expression, 0)
-	var exp expressions.Expression
-	for exp, err = ParseExpression(toks); err == nil; exp, err = ParseExpression(toks) {
-		if exp == nil {
+	for {
+		// the list may be empty, or end with a comma, before the bracket
+		// closing it
+		tok, ok := toks.Peek()
+		if ok && (tok.Type == tokens.RIGHT_PAREN || tok.Type == tokens.RIGHT_SQUARE_BRACKET) {
 			return exps, nil
 		}
 
+		var exp expressions.Expression
+		exp, err = ParseExpression(toks)
+		if err != nil {
+			return nil, err
+		}
+
 		exps = append(exps, exp)
-		tok, ok := toks.Peek()
+		tok, ok = toks.Peek()
 		if !ok {
 			return nil, &models.InterpreterError{
 				Message:        "after expression in expression list",
-				Underlying:     err,
 				SourceLocation: exp.SourceLocation(),
+				Underlying: &models.InterpreterError{
+					Message:        "expected comma or closing bracket",
+					SourceLocation: toks.CurrentSourceLocation(),
+				},
 			}
 		}
 
 		if tok.Type != tokens.COMMA {
-			break
+			return exps, nil
 		}
 
 		toks.Pop()
 	}
-	if err != nil {
-		return nil, err
-	}
-	return exps, nil
 }
@@ -1,6 +1,10 @@
 package parser
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
@@ -10,7 +14,14 @@ import (
 type ArrayLiteralExpression struct {
 	elemType types.Type
 	val      []expressions.Expression
-	loc      *models.SourceLocation
+	// spreads[i] is set if val[i] is a list whose elements are spread
+	// into the literal, as in "[...a, x]"; it is nil if there are none
+	spreads []bool
+	loc     *models.SourceLocation
+}
+
+func (ale *ArrayLiteralExpression) isSpread(i int) bool {
+	return ale.spreads != nil && ale.spreads[i]
 }
 
 func (ale *ArrayLiteralExpression) Type(tb types.TypeBindings) (types.Type, *models.InterpreterError) {
@@ -18,12 +29,23 @@ func (ale *ArrayLiteralExpression) Type(tb types.TypeBindings) (types.Type, *mod
 		ale.elemType = types.PrimitiveTypeAny
 	}
 
-	for _, v := range ale.val {
+	for i, v := range ale.val {
 		t, err := v.Type(tb)
 		if err != nil {
 			return nil, err
 		}
 
+		if ale.isSpread(i) {
+			listType, ok := t.(types.ListType)
+			if !ok {
+				return nil, &models.InterpreterError{
+					Message:        fmt.Sprintf("cannot spread type %s into a list", t),
+					SourceLocation: v.SourceLocation(),
+				}
+			}
+			t = listType.ElementType
+		}
+
 		aleSuper, innerErr := types.IsSuperTo(ale.elemType, t)
 		if innerErr != nil {
 			return nil, &models.InterpreterError{
@@ -43,15 +65,43 @@ func (ale *ArrayLiteralExpression) Type(tb types.TypeBindings) (types.Type, *mod
 	return types.List(ale.elemType), nil
 }
 
-func (ale *ArrayLiteralExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
+func (ale *ArrayLiteralExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	if innerErr := expressions.Allocate(ctx, len(ale.val)); innerErr != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in array literal",
+			Underlying:     innerErr,
+			SourceLocation: ale.SourceLocation(),
+		}
+	}
+
 	ret := make([]any, 0)
-	for _, v := range ale.val {
-		retVal, err := v.Evaluate(bindings)
+	for i, v := range ale.val {
+		retVal, err := v.Evaluate(ctx, bindings)
 		if err != nil {
 			return nil, err
 		}
 
-		ret = append(ret, retVal)
+		if !ale.isSpread(i) {
+			ret = append(ret, retVal)
+			continue
+		}
+
+		list, ok := retVal.([]any)
+		if !ok {
+			return nil, &models.InterpreterError{
+				Message:        fmt.Sprintf("expected list to spread; got %v", retVal),
+				SourceLocation: v.SourceLocation(),
+			}
+		}
+		// the list built must fit, with the elements still to come
+		if innerErr := expressions.Allocate(ctx, len(ret)+len(list)+len(ale.val)-i-1); innerErr != nil {
+			return nil, &models.InterpreterError{
+				Message:        "in array literal",
+				Underlying:     innerErr,
+				SourceLocation: ale.SourceLocation(),
+			}
+		}
+		ret = append(ret, list...)
 	}
 
 	return ret, nil
@@ -81,7 +131,7 @@ func parseArrayLiteral(toks *tokens.TokenStack) (exp expressions.Expression, err
 	}
 	toks.Pop()
 
-	exps, err := parseExpressions(toks)
+	exps, spreads, err := parseArrayElements(toks)
 	if err != nil {
 		return nil, err
 	}
@@ -121,9 +171,78 @@ func parseArrayLiteral(toks *tokens.TokenStack) (exp expressions.Expression, err
 
 	exp = &ArrayLiteralExpression{
 		val:      exps,
+		spreads:  spreads,
 		loc:      beginSourceLocation,
 		elemType: typ,
 	}
 
 	return exp, nil
 }
+
+// parseArrayElements parses the elements of an array literal, any of which
+// may be a list spread into it, returning which of them are spread, or nil
+// if none are
+func parseArrayElements(toks *tokens.TokenStack) (exps []expressions.Expression, spreads []bool, err *models.InterpreterError) {
+	exps = make([]expressions.Expression, 0)
+	for {
+		// the list may be empty, or end with a comma, before the bracket
+		// closing it
+		tok, ok := toks.Peek()
+		if ok && tok.Type == tokens.RIGHT_SQUARE_BRACKET {
+			return exps, spreads, nil
+		}
+
+		spread := ok && tok.Type == tokens.ELLIPSIS
+		if spread {
+			toks.Pop()
+			if spreads == nil {
+				spreads = make([]bool, len(exps))
+			}
+		}
+
+		var exp expressions.Expression
+		exp, err = ParseExpression(toks)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exps = append(exps, exp)
+		if spreads != nil {
+			spreads = append(spreads, spread)
+		}
+
+		tok, ok = toks.Peek()
+		if !ok {
+			return nil, nil, &models.InterpreterError{
+				Message:        "after expression in array literal",
+				SourceLocation: exp.SourceLocation(),
+				Underlying: &models.InterpreterError{
+					Message:        "expected comma or closing square bracket",
+					SourceLocation: toks.CurrentSourceLocation(),
+				},
+			}
+		}
+
+		if tok.Type != tokens.COMMA {
+			return exps, spreads, nil
+		}
+
+		toks.Pop()
+	}
+}
+
+func (ale *ArrayLiteralExpression) String() string {
+	elems := make([]string, 0, len(ale.val))
+	for i, v := range ale.val {
+		if ale.isSpread(i) {
+			elems = append(elems, fmt.Sprintf("...%v", v))
+			continue
+		}
+		elems = append(elems, fmt.Sprint(v))
+	}
+	str := "[" + strings.Join(elems, ", ") + "]"
+	if ale.elemType != nil && ale.elemType != types.PrimitiveTypeAny {
+		str += ale.elemType.String()
+	}
+	return str
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
@@ -32,8 +33,8 @@ func (aae *ArrayAccessExpression) Type(tb types.TypeBindings) (types.Type, *mode
 	return tList.ElementType, nil
 }
 
-func (aae *ArrayAccessExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	arr, err := aae.Array.Evaluate(bindings)
+func (aae *ArrayAccessExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	arr, err := aae.Array.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +46,7 @@ func (aae *ArrayAccessExpression) Evaluate(bindings expressions.Bindings) (any,
 		}
 	}
 
-	index, err := aae.Index.Evaluate(bindings)
+	index, err := aae.Index.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -114,8 +115,8 @@ func (ase *ArraySliceExpression) Type(tb types.TypeBindings) (types.Type, *model
 	return t, nil
 }
 
-func (ase *ArraySliceExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	arr, err := ase.Array.Evaluate(bindings)
+func (ase *ArraySliceExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	arr, err := ase.Array.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +130,7 @@ func (ase *ArraySliceExpression) Evaluate(bindings expressions.Bindings) (any, *
 
 	var beginInt int
 	if ase.Begin != nil {
-		begin, err := (*ase.Begin).Evaluate(bindings)
+		begin, err := (*ase.Begin).Evaluate(ctx, bindings)
 		if err != nil {
 			return nil, err
 		}
@@ -144,7 +145,7 @@ func (ase *ArraySliceExpression) Evaluate(bindings expressions.Bindings) (any, *
 
 	var endInt int = len(arrSlice)
 	if ase.End != nil {
-		end, err := (*ase.End).Evaluate(bindings)
+		end, err := (*ase.End).Evaluate(ctx, bindings)
 		if err != nil {
 			return nil, err
 		}
@@ -204,9 +205,7 @@ func parseArrayIndex(arr expressions.Expression, toks *tokens.TokenStack) (exp e
 		if err != nil {
 			return nil, err
 		}
-		if idxVal != nil {
-			idx = &idxVal
-		}
+		idx = &idxVal
 	}
 
 	tok, ok = toks.Peek()
@@ -224,13 +223,14 @@ func parseArrayIndex(arr expressions.Expression, toks *tokens.TokenStack) (exp e
 	if tok.Type == tokens.COLON {
 		toks.Pop()
 
+		// the end of the slice may be left out
 		var idx2 *expressions.Expression
-		var idxVal expressions.Expression
-		idxVal, err = ParseExpression(toks)
-		if err != nil {
-			return nil, err
-		}
-		if idxVal != nil {
+		if tok, ok := toks.Peek(); !ok || tok.Type != tokens.RIGHT_SQUARE_BRACKET {
+			var idxVal expressions.Expression
+			idxVal, err = ParseExpression(toks)
+			if err != nil {
+				return nil, err
+			}
 			idx2 = &idxVal
 		}
 
@@ -255,3 +255,19 @@ func parseArrayIndex(arr expressions.Expression, toks *tokens.TokenStack) (exp e
 		loc:   arr.SourceLocation(),
 	}, nil
 }
+
+func (aae *ArrayAccessExpression) String() string {
+	return fmt.Sprintf("%v[%v]", aae.Array, aae.Index)
+}
+
+func (ase *ArraySliceExpression) String() string {
+	str := fmt.Sprintf("%v[", ase.Array)
+	if ase.Begin != nil {
+		str += fmt.Sprint(*ase.Begin)
+	}
+	str += ":"
+	if ase.End != nil {
+		str += fmt.Sprint(*ase.End)
+	}
+	return str + "]"
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
@@ -45,8 +46,8 @@ func (ae *AsExpression) Type(tb types.TypeBindings) (types.Type, *models.Interpr
 	return ae.typ, nil
 }
 
-func (ae *AsExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	ret, err := ae.exp.Evaluate(bindings)
+func (ae *AsExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	ret, err := ae.exp.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -94,3 +95,7 @@ func (ae *AsExpression) Evaluate(bindings expressions.Bindings) (any, *models.In
 func (ae *AsExpression) SourceLocation() *models.SourceLocation {
 	return ae.exp.SourceLocation()
 }
+
+func (ae *AsExpression) String() string {
+	return fmt.Sprintf("(%v as %s)", ae.exp, ae.typ)
+}
@@ -2,9 +2,11 @@ package parser
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/values"
 	"github.com/brandonksides/grundfunken/tokens"
 )
 
@@ -75,7 +77,14 @@ func parseAtomic(toks *tokens.TokenStack) (exp expressions.Expression, err *mode
 
 		numStr += tok.Value
 
-		ret, innerErr := strconv.Atoi(numStr)
+		// literals too large for an int are parsed as *big.Int, and are
+		// only an error if overflow is an error when the program runs
+		var ret any
+		if strings.Contains(numStr, ".") {
+			ret, innerErr = strconv.ParseFloat(numStr, 64)
+		} else {
+			ret, innerErr = values.ParseInt(numStr, 0)
+		}
 		if innerErr != nil {
 			return nil, &models.InterpreterError{
 				Message:        "failed to parse number literal",
@@ -132,10 +141,16 @@ func parseAtomic(toks *tokens.TokenStack) (exp expressions.Expression, err *mode
 				Underlying:     innerErr,
 			}
 		}
-		exp, err = &LiteralExpression{
-			val: tok.Value,
-			loc: tok.SourceLocation,
-		}, nil
+		if len(tok.Parts) > 0 {
+			exp, err = parseInterpolation(tok)
+		} else {
+			exp, err = &LiteralExpression{
+				val: tok.Value,
+				loc: tok.SourceLocation,
+			}, nil
+		}
+	case tokens.CHAN:
+		exp, err = parseChannelExpression(toks)
 	case tokens.LET:
 		exp, err = parseLetExpression(toks)
 	case tokens.IF:
@@ -146,7 +161,10 @@ func parseAtomic(toks *tokens.TokenStack) (exp expressions.Expression, err *mode
 			return nil, err
 		}
 	default:
-		return nil, nil
+		return nil, &models.InterpreterError{
+			Message:        "unexpected token; expected expression",
+			SourceLocation: &tok.SourceLocation,
+		}
 	}
 	if err != nil {
 		return nil, err
@@ -267,6 +285,32 @@ func parseAtomic(toks *tokens.TokenStack) (exp expressions.Expression, err *mode
 				typ:   castType,
 				asLoc: asLoc,
 			}
+		case tokens.WITH:
+			withLoc := tok.SourceLocation
+			toks.Pop()
+
+			tok, ok := toks.Peek()
+			if !ok || tok.Type != tokens.LEFT_SQUIGGLY_BRACKET {
+				return nil, &models.InterpreterError{
+					Message:        "in \"with\" expression",
+					SourceLocation: &withLoc,
+					Underlying: &models.InterpreterError{
+						Message:        "expected object literal of the fields to update",
+						SourceLocation: toks.CurrentSourceLocation(),
+					},
+				}
+			}
+
+			update, err := parseObjectLiteralExpression(toks)
+			if err != nil {
+				return nil, err
+			}
+
+			exp = &WithExpression{
+				Object:  exp,
+				Update:  update,
+				withLoc: withLoc,
+			}
 		default:
 			shouldBreak = true
 		}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/models/values"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+// ChannelExpression creates a new channel each time it is evaluated, e.g.
+// "chan int" for an unbuffered channel or "chan[4] int" for one that
+// buffers up to four values.
+type ChannelExpression struct {
+	ElementType types.Type
+	Size        int
+	loc         models.SourceLocation
+}
+
+func (ce *ChannelExpression) Type(tb types.TypeBindings) (types.Type, *models.InterpreterError) {
+	return types.Chan(ce.ElementType), nil
+}
+
+func (ce *ChannelExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	if innerErr := expressions.Allocate(ctx, ce.Size+1); innerErr != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in channel expression",
+			Underlying:     innerErr,
+			SourceLocation: ce.SourceLocation(),
+		}
+	}
+
+	return values.NewChan(ce.ElementType, ce.Size), nil
+}
+
+func (ce *ChannelExpression) SourceLocation() *models.SourceLocation {
+	ret := ce.loc
+	return &ret
+}
+
+func (ce *ChannelExpression) String() string {
+	if ce.Size > 0 {
+		return fmt.Sprintf("chan[%d] %s", ce.Size, ce.ElementType)
+	}
+	return "chan " + ce.ElementType.String()
+}
+
+func parseChannelExpression(toks *tokens.TokenStack) (expressions.Expression, *models.InterpreterError) {
+	tok, innerErr := toks.Pop()
+	if innerErr != nil {
+		return nil, &models.InterpreterError{
+			Message:        "expected channel expression",
+			SourceLocation: toks.CurrentSourceLocation(),
+			Underlying:     innerErr,
+		}
+	}
+
+	if tok.Type != tokens.CHAN {
+		return nil, &models.InterpreterError{
+			Message:        "unexpected token; expected \"chan\"",
+			SourceLocation: &tok.SourceLocation,
+		}
+	}
+
+	ret := &ChannelExpression{
+		loc: tok.SourceLocation,
+	}
+
+	// the buffer size, if any, comes in square brackets before the element
+	// type; brackets around anything else are a list element type
+	tok, ok := toks.Peek()
+	if ok && tok.Type == tokens.LEFT_SQUARE_BRACKET {
+		bracketLoc := tok.SourceLocation
+		toks.Pop()
+
+		tok, ok = toks.Peek()
+		if !ok || tok.Type != tokens.NUMBER {
+			elemType, err := parseType(toks)
+			if err != nil {
+				return nil, &models.InterpreterError{
+					Message:        "in channel expression",
+					Underlying:     err,
+					SourceLocation: &bracketLoc,
+				}
+			}
+			if err := expectClosingSquareBracket(toks, bracketLoc); err != nil {
+				return nil, err
+			}
+			ret.ElementType = types.List(elemType)
+			return ret, nil
+		}
+		toks.Pop()
+
+		size, innerErr := strconv.Atoi(tok.Value)
+		if innerErr != nil {
+			return nil, &models.InterpreterError{
+				Message:        "failed to parse channel buffer size",
+				Underlying:     innerErr,
+				SourceLocation: &tok.SourceLocation,
+			}
+		}
+		ret.Size = size
+
+		if err := expectClosingSquareBracket(toks, bracketLoc); err != nil {
+			return nil, err
+		}
+	}
+
+	elemType, err := parseAtomicType(toks)
+	if err != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in channel expression",
+			Underlying:     err,
+			SourceLocation: ret.SourceLocation(),
+		}
+	}
+	ret.ElementType = elemType
+
+	return ret, nil
+}
+
+func expectClosingSquareBracket(toks *tokens.TokenStack, openLoc models.SourceLocation) *models.InterpreterError {
+	tok, innerErr := toks.Pop()
+	if innerErr != nil {
+		return &models.InterpreterError{
+			Message:        "to terminate square brackets",
+			SourceLocation: &openLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "expected closing square bracket",
+				Underlying:     innerErr,
+				SourceLocation: toks.CurrentSourceLocation(),
+			},
+		}
+	}
+
+	if tok.Type != tokens.RIGHT_SQUARE_BRACKET {
+		return &models.InterpreterError{
+			Message:        "to terminate square brackets",
+			SourceLocation: &openLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "unexpected token; expected closing square bracket",
+				SourceLocation: &tok.SourceLocation,
+			},
+		}
+	}
+
+	return nil
+}
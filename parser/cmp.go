@@ -1,11 +1,13 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/models/values"
 	"github.com/brandonksides/grundfunken/tokens"
 )
 
@@ -50,68 +52,60 @@ func (ce *CmpExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 		return nil, err
 	}
 
-	if firstType != types.PrimitiveTypeInt {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to type %s", ce.op.Type.String(), firstType.String()),
-			SourceLocation: ce.first.SourceLocation(),
-		}
-	}
-
 	secondType, err := ce.second.Type(tb)
 	if err != nil {
 		return nil, err
 	}
 
-	if secondType != types.PrimitiveTypeInt {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to type %s", ce.op.Type.String(), secondType.String()),
-			SourceLocation: ce.second.SourceLocation(),
-		}
+	_, err = numericType(ce.op.Type.String(), ce.first, ce.second, firstType, secondType)
+	if err != nil {
+		return nil, err
 	}
 
 	return types.PrimitiveTypeBool, nil
 }
 
-func (ce *CmpExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	v1, err := ce.first.Evaluate(bindings)
+func (ce *CmpExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	v1, err := ce.first.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
 
-	v2, err := ce.second.Evaluate(bindings)
+	v2, err := ce.second.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
 
-	v1Int, ok := v1.(int)
-	if !ok {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("expected int; got %v", v1),
-			SourceLocation: ce.first.SourceLocation(),
-		}
+	isFloat, err := numericOperands(ce.op.Type.String(), ce.first, ce.second, v1, v2)
+	if err != nil {
+		return nil, err
 	}
 
-	v2Int, ok := v2.(int)
-	if !ok {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("expected int; got %v", v2),
-			SourceLocation: ce.second.SourceLocation(),
-		}
+	if isFloat {
+		return compare(ce.op.Type, v1.(float64), v2.(float64), ce.op.SourceLocation)
+	}
+	i1, ok1 := v1.(int)
+	i2, ok2 := v2.(int)
+	if ok1 && ok2 {
+		return compare(ce.op.Type, i1, i2, ce.op.SourceLocation)
 	}
+	return compare(ce.op.Type, values.BigInt(v1).Cmp(values.BigInt(v2)), 0, ce.op.SourceLocation)
+}
 
-	switch ce.op.Type {
+func compare[T int | float64](op CmpOpType, v1, v2 T, opLoc models.SourceLocation) (any, *models.InterpreterError) {
+	switch op {
 	case CMP_OP_TYPE_LESS:
-		return v1Int < v2Int, nil
+		return v1 < v2, nil
 	case CMP_OP_TYPE_LESS_EQUAL:
-		return v1Int <= v2Int, nil
+		return v1 <= v2, nil
 	case CMP_OP_GREATER_EQUAL:
-		return v1Int >= v2Int, nil
+		return v1 >= v2, nil
 	case CMP_OP_GREATER:
-		return v1Int > v2Int, nil
+		return v1 > v2, nil
 	default:
 		return nil, &models.InterpreterError{
 			Message:        "invalid operator",
-			SourceLocation: &ce.op.SourceLocation,
+			SourceLocation: &opLoc,
 		}
 	}
 }
@@ -194,3 +188,7 @@ func foldCmp(first expressions.Expression, toks *tokens.TokenStack) (exp express
 		second: exp2,
 	}, nil
 }
+
+func (ce *CmpExpression) String() string {
+	return fmt.Sprintf("(%v %s %v)", ce.first, ce.op.Type.String(), ce.second)
+}
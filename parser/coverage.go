@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+)
+
+// Coverage counts the evaluations of every node of the expressions
+// instrumented with it.  It is safe to use from concurrently running
+// tasks.
+type Coverage struct {
+	mu    sync.Mutex
+	files map[string]*FileCoverage
+}
+
+// FileCoverage holds the counters of the nodes of a single file
+type FileCoverage struct {
+	// Path is the path of the file, as it was read
+	Path  string
+	Lines []string
+	// Counters are the counters of the nodes of the file, in the order in
+	// which the nodes were instrumented
+	Counters []*Counter
+	// Branches are the "if" and "match" expressions of the file
+	Branches []Branch
+}
+
+// Counter counts the evaluations of a single node
+type Counter struct {
+	SourceLocation models.SourceLocation
+	count          atomic.Int64
+}
+
+// Count returns the number of times the node has been evaluated
+func (c *Counter) Count() int64 {
+	return c.count.Load()
+}
+
+// Branch is an "if" or "match" expression, with a counter for each of
+// its arms: the "then" and "else" arms of an "if", or the arms of a
+// "match" in order
+type Branch struct {
+	SourceLocation models.SourceLocation
+	Arms           []*Counter
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{
+		files: make(map[string]*FileCoverage),
+	}
+}
+
+// Files returns the coverage of every file instrumented, ordered by path
+func (cov *Coverage) Files() []*FileCoverage {
+	cov.mu.Lock()
+	defer cov.mu.Unlock()
+
+	ret := make([]*FileCoverage, 0, len(cov.files))
+	for _, file := range cov.files {
+		ret = append(ret, file)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Path < ret[j].Path
+	})
+	return ret
+}
+
+// Instrument returns an expression equivalent to the given one, the
+// expression of the file at the given path, that counts the evaluations of
+// each of its nodes.  Instrumenting the same file again, as when it is
+// imported more than once, reuses its counters.
+func (cov *Coverage) Instrument(path string, lines []string, exp expressions.Expression) expressions.Expression {
+	cov.mu.Lock()
+	defer cov.mu.Unlock()
+
+	file, ok := cov.files[path]
+	if !ok {
+		file = &FileCoverage{Path: path, Lines: lines}
+		cov.files[path] = file
+	}
+
+	in := &instrumenter{file: file}
+	return in.instrument(exp)
+}
+
+// instrumenter walks an expression in a fixed order, so that the nodes of
+// a file instrumented more than once get the same counters each time
+type instrumenter struct {
+	file     *FileCoverage
+	counters int
+	branches int
+}
+
+func (in *instrumenter) counter(loc models.SourceLocation) *Counter {
+	if in.counters < len(in.file.Counters) {
+		ret := in.file.Counters[in.counters]
+		in.counters++
+		return ret
+	}
+
+	ret := &Counter{SourceLocation: loc}
+	in.file.Counters = append(in.file.Counters, ret)
+	in.counters++
+	return ret
+}
+
+func (in *instrumenter) branch(loc *models.SourceLocation, arms ...*countedExpression) {
+	if in.branches < len(in.file.Branches) {
+		in.branches++
+		return
+	}
+	if loc == nil {
+		loc = &models.SourceLocation{}
+	}
+
+	counters := make([]*Counter, 0, len(arms))
+	for _, arm := range arms {
+		counters = append(counters, arm.counter)
+	}
+	in.file.Branches = append(in.file.Branches, Branch{
+		SourceLocation: *loc,
+		Arms:           counters,
+	})
+	in.branches++
+}
+
+func (in *instrumenter) instrument(exp expressions.Expression) expressions.Expression {
+	ret := in.instrumentChildren(exp)
+	// a let binding only patches the closures of its own function
+	// expressions, so they must stay function expressions; their bodies
+	// are counted instead
+	if _, ok := ret.(*FunctionExpression); ok {
+		return ret
+	}
+	return in.count(ret)
+}
+
+// count wraps exp with a counter at its location
+func (in *instrumenter) count(exp expressions.Expression) *countedExpression {
+	loc := exp.SourceLocation()
+	if loc == nil {
+		loc = &models.SourceLocation{}
+	}
+	return &countedExpression{
+		Expression: exp,
+		counter:    in.counter(*loc),
+	}
+}
+
+func (in *instrumenter) instrumentChildren(exp expressions.Expression) expressions.Expression {
+	switch exp := exp.(type) {
+	case *IfExpression:
+		cond := in.instrument(exp.Condition)
+		// the arms are always counted, even if they are functions, so
+		// that every branch has a counter
+		then := in.count(in.instrumentChildren(exp.Then))
+		els := in.count(in.instrumentChildren(exp.Else))
+		in.branch(exp.loc, then, els)
+		return &IfExpression{
+			Condition: cond,
+			Then:      then,
+			Else:      els,
+			loc:       exp.loc,
+		}
+	case *MatchExpression:
+		on := in.instrument(exp.On)
+		arms := make([]MatchArm, 0, len(exp.Arms))
+		counted := make([]*countedExpression, 0, len(exp.Arms))
+		for _, arm := range exp.Arms {
+			armExp := in.count(in.instrumentChildren(arm.Exp))
+			counted = append(counted, armExp)
+			arms = append(arms, MatchArm{
+				Type: arm.Type,
+				Exp:  armExp,
+			})
+		}
+		in.branch(exp.loc, counted...)
+		return &MatchExpression{
+			On:   on,
+			Arms: arms,
+			As:   exp.As,
+			loc:  exp.loc,
+		}
+	default:
+		return mapChildren(exp, in.instrument)
+	}
+}
+
+// countedExpression counts the evaluations of the expression it wraps,
+// which it otherwise behaves exactly as
+type countedExpression struct {
+	expressions.Expression
+	counter *Counter
+}
+
+func (ce *countedExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	ce.counter.count.Add(1)
+	return ce.Expression.Evaluate(ctx, bindings)
+}
+
+func (ce *countedExpression) String() string {
+	return fmt.Sprint(ce.Expression)
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+)
+
+// FieldDoc holds the documentation found in the source of a field of a
+// module's exported object
+type FieldDoc struct {
+	Name string
+	// Doc is the doc comment on the field, or else on the let binding
+	// the field is set to
+	Doc string
+	// Args are the declared arguments of the field, if it is set to a
+	// function literal
+	Args []types.Arg
+}
+
+// ExportedDocs returns the documentation of the fields of the object
+// literal a module evaluates to, looking through the let expressions
+// around it, by field name.  It returns nil for modules whose result is
+// not an object literal.
+func ExportedDocs(exp expressions.Expression) map[string]FieldDoc {
+	bound := make(map[string]BindingExpression)
+	for {
+		switch e := exp.(type) {
+		case *LetExpression:
+			// later bindings shadow earlier ones, just as they would
+			// during evaluation
+			for _, binding := range e.LetClauses {
+				if binding.Pattern != nil {
+					for _, name := range binding.Pattern.Names() {
+						delete(bound, name)
+					}
+					continue
+				}
+				bound[binding.Identifier] = binding
+			}
+			exp = e.InClause
+		case *ObjectLiteralExpression:
+			docs := make(map[string]FieldDoc, len(e.Fields))
+			for key, field := range e.Fields {
+				fieldDoc := FieldDoc{
+					Name: key,
+					Doc:  e.Docs[key],
+				}
+
+				if ident, ok := field.(*IdentifierExpression); ok {
+					if binding, ok := bound[ident.name]; ok {
+						if fieldDoc.Doc == "" {
+							fieldDoc.Doc = binding.Doc
+						}
+						field = binding.Expression
+					}
+				}
+				if funcExp, ok := field.(*FunctionExpression); ok {
+					fieldDoc.Args = funcExp.Args
+				}
+
+				docs[key] = fieldDoc
+			}
+			return docs
+		default:
+			return nil
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package parser
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
@@ -39,22 +43,30 @@ func (ee *EqExpression) Type(tb types.TypeBindings) (types.Type, *models.Interpr
 	return types.PrimitiveTypeBool, nil
 }
 
-func (ee *EqExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	v1, err := ee.Left.Evaluate(bindings)
+func (ee *EqExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	v1, err := ee.Left.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
 
-	v2, err := ee.Right.Evaluate(bindings)
+	v2, err := ee.Right.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
 
+	equal := v1 == v2
+	// integers too large for an int are never equal to an int
+	if b1, ok := v1.(*big.Int); ok {
+		if b2, ok := v2.(*big.Int); ok {
+			equal = b1.Cmp(b2) == 0
+		}
+	}
+
 	switch ee.Op.Type {
 	case EQ_OP_EQUAL:
-		return v1 == v2, nil
+		return equal, nil
 	case EQ_OP_NOT_EQUAL:
-		return v1 != v2, nil
+		return !equal, nil
 	default:
 		return nil, &models.InterpreterError{
 			Message:        "invalid operator",
@@ -133,3 +145,11 @@ func parseEqOp(toks *tokens.TokenStack) (op *EqOp, err *models.InterpreterError)
 
 	return op, nil
 }
+
+func (ee *EqExpression) String() string {
+	op := "is"
+	if ee.Op.Type == EQ_OP_NOT_EQUAL {
+		op = "is not"
+	}
+	return fmt.Sprintf("(%v %s %v)", ee.Left, op, ee.Right)
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
@@ -39,8 +40,8 @@ func (fae *FieldAccessExpression) Type(tb types.TypeBindings) (types.Type, *mode
 	return fieldType, nil
 }
 
-func (fae *FieldAccessExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	obj, err := fae.Object.Evaluate(bindings)
+func (fae *FieldAccessExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	obj, err := fae.Object.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -65,3 +66,7 @@ func (fae *FieldAccessExpression) Evaluate(bindings expressions.Bindings) (any,
 func (fae *FieldAccessExpression) SourceLocation() *models.SourceLocation {
 	return fae.Object.SourceLocation()
 }
+
+func (fae *FieldAccessExpression) String() string {
+	return fmt.Sprintf("%v.%s", fae.Object, fae.Field)
+}
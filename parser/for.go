@@ -1,19 +1,28 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/models/values"
 	"github.com/brandonksides/grundfunken/tokens"
 )
 
 type ForExpression struct {
 	ForClause  expressions.Expression
 	Identifier string
-	InClause   expressions.Expression
-	loc        *models.SourceLocation
+	// Pattern, if set, takes each element apart and binds its pieces, in
+	// which case Identifier is empty
+	Pattern  Pattern
+	InClause expressions.Expression
+	Parallel *ParallelOptions
+	// seqElemType is the type of the elements of the sequence built when
+	// the in clause is a sequence, once the expression is type checked
+	seqElemType types.Type
+	loc         *models.SourceLocation
 }
 
 func (fe *ForExpression) Type(tb types.TypeBindings) (types.Type, *models.InterpreterError) {
@@ -22,10 +31,15 @@ func (fe *ForExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 		return nil, err
 	}
 
-	inTypeList, ok := inType.(types.ListType)
-	if !ok {
+	var elemType types.Type
+	switch inType := inType.(type) {
+	case types.ListType:
+		elemType = inType.ElementType
+	case types.SeqType:
+		elemType = inType.ElementType
+	default:
 		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("for expression in clause must evaluate to a list; got %s", inType),
+			Message:        fmt.Sprintf("for expression in clause must evaluate to a list or sequence; got %s", inType),
 			SourceLocation: fe.InClause.SourceLocation(),
 		}
 	}
@@ -34,17 +48,35 @@ func (fe *ForExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 	for k, v := range tb {
 		innerTB[k] = v
 	}
-	innerTB[fe.Identifier] = inTypeList.ElementType
+	if fe.Pattern == nil {
+		innerTB[fe.Identifier] = elemType
+	} else if err := fe.Pattern.bindTypes(elemType, innerTB); err != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in for expression",
+			SourceLocation: fe.SourceLocation(),
+			Underlying:     err,
+		}
+	}
 
 	forType, err := fe.ForClause.Type(innerTB)
 	if err != nil {
 		return nil, err
 	}
 
+	if _, ok := inType.(types.SeqType); ok {
+		if fe.Parallel != nil {
+			return nil, &models.InterpreterError{
+				Message:        fmt.Sprintf("parallel for expression in clause must evaluate to a list; got %s", inType),
+				SourceLocation: fe.InClause.SourceLocation(),
+			}
+		}
+		fe.seqElemType = forType
+		return types.Seq(forType), nil
+	}
 	return types.List(forType), nil
 }
 
-func (fe *ForExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
+func (fe *ForExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
 	ret := make([]any, 0)
 
 	innerBindings := make(expressions.Bindings)
@@ -52,11 +84,15 @@ func (fe *ForExpression) Evaluate(bindings expressions.Bindings) (any, *models.I
 		innerBindings[k] = v
 	}
 
-	iterableExp, err := fe.InClause.Evaluate(innerBindings)
+	iterableExp, err := fe.InClause.Evaluate(ctx, innerBindings)
 	if err != nil {
 		return nil, err
 	}
 
+	if seq, ok := iterableExp.(types.Sequence); ok {
+		return fe.evaluateSeq(seq, bindings), nil
+	}
+
 	iterableExpArr, ok := iterableExp.([]any)
 	if !ok {
 		return nil, &models.InterpreterError{
@@ -65,9 +101,27 @@ func (fe *ForExpression) Evaluate(bindings expressions.Bindings) (any, *models.I
 		}
 	}
 
+	if innerErr := expressions.Allocate(ctx, len(iterableExpArr)); innerErr != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in for expression",
+			Underlying:     innerErr,
+			SourceLocation: fe.SourceLocation(),
+		}
+	}
+
+	if fe.Parallel != nil {
+		return fe.evaluateParallel(ctx, bindings, iterableExpArr)
+	}
+
 	for _, v := range iterableExpArr {
-		innerBindings[fe.Identifier] = v
-		retVal, err := fe.ForClause.Evaluate(innerBindings)
+		if err := expressions.Step(ctx, fe.SourceLocation()); err != nil {
+			return nil, err
+		}
+
+		if err := fe.bind(v, innerBindings); err != nil {
+			return nil, err
+		}
+		retVal, err := fe.ForClause.Evaluate(ctx, innerBindings)
 		if err != nil {
 			return nil, err
 		}
@@ -78,6 +132,42 @@ func (fe *ForExpression) Evaluate(bindings expressions.Bindings) (any, *models.I
 	return ret, nil
 }
 
+// evaluateSeq lazily maps the for clause over a sequence, evaluating it
+// for each element only as the resulting sequence is iterated over
+func (fe *ForExpression) evaluateSeq(seq types.Sequence, bindings expressions.Bindings) types.Sequence {
+	return values.NewSeq(fe.seqElemType, func(ctx context.Context, yield func(any) (bool, error)) error {
+		return seq.Iterate(ctx, func(elem any) (bool, error) {
+			if err := expressions.Step(ctx, fe.SourceLocation()); err != nil {
+				return false, err
+			}
+
+			innerBindings := make(expressions.Bindings, len(bindings)+1)
+			for k, v := range bindings {
+				innerBindings[k] = v
+			}
+			if err := fe.bind(elem, innerBindings); err != nil {
+				return false, err
+			}
+
+			retVal, err := fe.ForClause.Evaluate(ctx, innerBindings)
+			if err != nil {
+				return false, err
+			}
+			return yield(retVal)
+		})
+	})
+}
+
+// bind binds the identifier or pattern of the for expression to an
+// element
+func (fe *ForExpression) bind(elem any, bindings expressions.Bindings) *models.InterpreterError {
+	if fe.Pattern != nil {
+		return fe.Pattern.bind(elem, bindings)
+	}
+	bindings[fe.Identifier] = elem
+	return nil
+}
+
 func (fe *ForExpression) SourceLocation() *models.SourceLocation {
 	return fe.loc
 }
@@ -103,6 +193,14 @@ func parseForExpression(exp1 expressions.Expression, toks *tokens.TokenStack) (e
 		}
 	}
 	identifier := tok.Value
+	var pattern Pattern
+	if isPatternStart(tok) {
+		pattern, err = parsePattern(tok, toks)
+		if err != nil {
+			return nil, err
+		}
+		identifier = ""
+	}
 
 	tok, ok = toks.Peek()
 	if !ok {
@@ -125,10 +223,31 @@ func parseForExpression(exp1 expressions.Expression, toks *tokens.TokenStack) (e
 		return nil, err
 	}
 
+	parallel, err := parseParallelOptions(toks)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ForExpression{
 		ForClause:  exp1,
 		Identifier: identifier,
+		Pattern:    pattern,
 		InClause:   exp2,
+		Parallel:   parallel,
 		loc:        beginLoc,
 	}, nil
 }
+
+func (fe *ForExpression) String() string {
+	binding := fe.Identifier
+	if fe.Pattern != nil {
+		binding = fe.Pattern.String()
+	}
+	if fe.Parallel != nil {
+		if fe.Parallel.Workers > 0 {
+			return fmt.Sprintf("(%v for %s in %v parallel[%d])", fe.ForClause, binding, fe.InClause, fe.Parallel.Workers)
+		}
+		return fmt.Sprintf("(%v for %s in %v parallel)", fe.ForClause, binding, fe.InClause)
+	}
+	return fmt.Sprintf("(%v for %s in %v)", fe.ForClause, binding, fe.InClause)
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
@@ -10,35 +13,69 @@ import (
 )
 
 type FunctionExpression struct {
-	Args    []types.Arg
-	RetType types.Type
-	body    expressions.Expression
-	loc     *models.SourceLocation
+	// Name is the identifier the function is bound to by a let binding
+	// or object field, if any
+	Name string
+	Args []types.Arg
+	// ArgPatterns holds, for each argument, the pattern that takes it
+	// apart, or nil if it is bound to its name
+	ArgPatterns []Pattern
+	RetType     types.Type
+	Memo        *MemoOptions
+	body        expressions.Expression
+	loc         *models.SourceLocation
 }
 
 type FuncValue struct {
 	Bindings expressions.Bindings
 	Exp      FunctionExpression
+	cache    *memoCache
 }
 
-func (f *FuncValue) Call(args []any) (any, error) {
+func (f *FuncValue) Call(ctx context.Context, args []any) (any, error) {
+	ctx, exit := expressions.EnterCall(ctx, f.Exp.Name, f.Exp.loc, args)
+	ret, err := f.call(ctx, args)
+	exit(ret, err)
+	return ret, err
+}
+
+func (f *FuncValue) call(ctx context.Context, args []any) (any, error) {
 	if len(args) != len(f.Exp.Args) {
 		return nil, &models.InterpreterError{
 			Message:        fmt.Sprintf("expected %d arguments, got %d", len(f.Exp.Args), len(args)),
 			SourceLocation: f.Exp.loc,
 		}
 	}
+
+	var key string
+	if f.cache != nil {
+		key = memoKey(args)
+		if ret, ok := f.cache.get(key, args); ok {
+			return ret, nil
+		}
+	}
+
 	newBindings := make(expressions.Bindings)
 	for k, v := range f.Bindings {
 		newBindings[k] = v
 	}
 	for i, arg := range f.Exp.Args {
+		if pattern := f.Exp.ArgPatterns[i]; pattern != nil {
+			if err := pattern.bind(args[i], newBindings); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		newBindings[arg.Name] = args[i]
 	}
-	ret, err := f.Exp.body.Evaluate(newBindings)
+	ret, err := f.Exp.body.Evaluate(ctx, newBindings)
 	if err != nil {
 		return nil, err
 	}
+
+	if f.cache != nil {
+		f.cache.put(key, args, ret)
+	}
 	return ret, nil
 }
 
@@ -51,7 +88,19 @@ func (f *FuncValue) Return() types.Type {
 }
 
 func (f *FuncValue) String() string {
-	return fmt.Sprintf("func(%v) %v { ... }", f.Exp.Args, f.Exp.RetType)
+	args := make([]string, 0, len(f.Exp.Args))
+	for _, arg := range f.Exp.Args {
+		args = append(args, arg.String())
+	}
+	return fmt.Sprintf("func(%s) %v { ... }", strings.Join(args, ", "), f.Exp.RetType)
+}
+
+// nameFunction names exp after the identifier it is bound to, if it is a
+// function expression without a name
+func nameFunction(exp expressions.Expression, name string) {
+	if funcExp, ok := exp.(*FunctionExpression); ok && funcExp.Name == "" {
+		funcExp.Name = name
+	}
 }
 
 func (fe *FunctionExpression) Type(tb types.TypeBindings) (types.Type, *models.InterpreterError) {
@@ -61,8 +110,18 @@ func (fe *FunctionExpression) Type(tb types.TypeBindings) (types.Type, *models.I
 	}
 
 	argTypes := make([]types.Type, 0)
-	for _, arg := range fe.Args {
+	for i, arg := range fe.Args {
 		argTypes = append(argTypes, arg.Type)
+		if pattern := fe.ArgPatterns[i]; pattern != nil {
+			if err := pattern.bindTypes(arg.Type, innerTB); err != nil {
+				return nil, &models.InterpreterError{
+					Message:        "in function arguments",
+					SourceLocation: fe.loc,
+					Underlying:     err,
+				}
+			}
+			continue
+		}
 		innerTB[arg.Name] = arg.Type
 	}
 
@@ -90,17 +149,25 @@ func (fe *FunctionExpression) Type(tb types.TypeBindings) (types.Type, *models.I
 	return types.Func(argTypes, retType), nil
 }
 
-func (fe *FunctionExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
+func (fe *FunctionExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
 	// capture the current bindings
 	retBindings := make(expressions.Bindings)
 	for k, v := range bindings {
 		retBindings[k] = v
 	}
 
-	return &FuncValue{
+	ret := &FuncValue{
 		Exp:      *fe,
 		Bindings: retBindings,
-	}, nil
+	}
+	if fe.Memo != nil {
+		// each closure gets its own cache, since the same
+		// arguments may give different results in different
+		// scopes
+		ret.cache = newMemoCache(fe.Memo.Size)
+	}
+
+	return ret, nil
 }
 
 func (fe *FunctionExpression) SourceLocation() *models.SourceLocation {
@@ -126,6 +193,11 @@ func parseFunction(toks *tokens.TokenStack) (exp expressions.Expression, err *mo
 	}
 	toks.Pop()
 
+	memo, err := parseMemoOptions(toks)
+	if err != nil {
+		return nil, err
+	}
+
 	tok, ok = toks.Peek()
 	if !ok {
 		return nil, &models.InterpreterError{
@@ -143,20 +215,32 @@ func parseFunction(toks *tokens.TokenStack) (exp expressions.Expression, err *mo
 	toks.Pop()
 
 	args := make([]types.Arg, 0)
+	argPatterns := make([]Pattern, 0)
 	var popErr error
 	for tok, popErr = toks.Pop(); popErr == nil; tok, popErr = toks.Pop() {
 		if tok.Type == tokens.RIGHT_PAREN {
 			break
 		}
 
-		if tok.Type != tokens.IDENTIFIER {
+		var argPattern Pattern
+		if isPatternStart(tok) {
+			argPattern, err = parsePattern(tok, toks)
+			if err != nil {
+				return nil, err
+			}
+		} else if tok.Type != tokens.IDENTIFIER {
 			return nil, &models.InterpreterError{
-				Message:        "unexpected token; expected identifier",
+				Message:        "unexpected token; expected identifier or pattern",
 				SourceLocation: &tok.SourceLocation,
 			}
 		}
 		argLoc := tok.SourceLocation
 		argName := tok.Value
+		argDoc := tok.Doc
+		if argPattern != nil {
+			// the argument is shown as its pattern in docs and errors
+			argName = argPattern.String()
+		}
 
 		tok, ok := toks.Peek()
 		if !ok {
@@ -166,7 +250,7 @@ func parseFunction(toks *tokens.TokenStack) (exp expressions.Expression, err *mo
 				Underlying: &models.InterpreterError{
 					Message:        "expected comma or closing parenthesis",
 					Underlying:     popErr,
-					SourceLocation: &tok.SourceLocation,
+					SourceLocation: toks.CurrentSourceLocation(),
 				},
 			}
 		}
@@ -184,7 +268,8 @@ func parseFunction(toks *tokens.TokenStack) (exp expressions.Expression, err *mo
 			}
 		}
 
-		args = append(args, types.Arg{Name: argName, Type: argType})
+		args = append(args, types.Arg{Name: argName, Type: argType, Doc: argDoc})
+		argPatterns = append(argPatterns, argPattern)
 
 		tok, innerErr := toks.Pop()
 		if innerErr != nil {
@@ -238,9 +323,97 @@ func parseFunction(toks *tokens.TokenStack) (exp expressions.Expression, err *mo
 	}
 
 	return &FunctionExpression{
-		Args:    args,
-		RetType: retType,
-		body:    exp,
-		loc:     beginLoc,
+		Args:        args,
+		ArgPatterns: argPatterns,
+		RetType:     retType,
+		Memo:        memo,
+		body:        exp,
+		loc:         beginLoc,
 	}, nil
 }
+
+// parseMemoOptions parses the optional "memo" modifier following the
+// "func" keyword, with an optional cache size in square brackets, as in
+// "func memo[100](n int) int ..."
+func parseMemoOptions(toks *tokens.TokenStack) (*MemoOptions, *models.InterpreterError) {
+	tok, ok := toks.Peek()
+	if !ok || tok.Type != tokens.IDENTIFIER || tok.Value != "memo" {
+		return nil, nil
+	}
+	memoLoc := tok.SourceLocation
+	toks.Pop()
+
+	memo := &MemoOptions{}
+
+	tok, ok = toks.Peek()
+	if !ok || tok.Type != tokens.LEFT_SQUARE_BRACKET {
+		return memo, nil
+	}
+	toks.Pop()
+
+	tok, innerErr := toks.Pop()
+	if innerErr != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in memo modifier",
+			SourceLocation: &memoLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "expected cache size",
+				SourceLocation: toks.CurrentSourceLocation(),
+				Underlying:     innerErr,
+			},
+		}
+	}
+
+	size, convErr := strconv.Atoi(tok.Value)
+	if tok.Type != tokens.NUMBER || convErr != nil || size <= 0 {
+		return nil, &models.InterpreterError{
+			Message:        "in memo modifier",
+			SourceLocation: &memoLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "unexpected token; expected positive integer cache size",
+				SourceLocation: &tok.SourceLocation,
+			},
+		}
+	}
+	memo.Size = size
+
+	tok, innerErr = toks.Pop()
+	if innerErr != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in memo modifier",
+			SourceLocation: &memoLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "expected closing square bracket",
+				SourceLocation: toks.CurrentSourceLocation(),
+				Underlying:     innerErr,
+			},
+		}
+	}
+	if tok.Type != tokens.RIGHT_SQUARE_BRACKET {
+		return nil, &models.InterpreterError{
+			Message:        "in memo modifier",
+			SourceLocation: &memoLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "unexpected token; expected closing square bracket",
+				SourceLocation: &tok.SourceLocation,
+			},
+		}
+	}
+
+	return memo, nil
+}
+
+func (fe *FunctionExpression) String() string {
+	args := make([]string, 0, len(fe.Args))
+	for _, arg := range fe.Args {
+		args = append(args, arg.String())
+	}
+	modifier := ""
+	if fe.Memo != nil {
+		modifier = " memo"
+		if fe.Memo.Size > 0 {
+			modifier += fmt.Sprintf("[%d]", fe.Memo.Size)
+		}
+	}
+	return fmt.Sprintf("func%s(%s) %s %v", modifier, strings.Join(args, ", "), fe.RetType, fe.body)
+}
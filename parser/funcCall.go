@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
@@ -35,11 +37,21 @@ func (fce *FunctionCallExpression) Type(tb types.TypeBindings) (types.Type, *mod
 		}
 	}
 
-	for i, arg := range fce.Args {
+	argTypes := make([]types.Type, 0, len(fce.Args))
+	for _, arg := range fce.Args {
 		t, err := arg.Type(tb)
 		if err != nil {
 			return nil, err
 		}
+		argTypes = append(argTypes, t)
+	}
+
+	if types.HasVars(funType) {
+		funType = types.Instantiate(funType, argTypes)
+	}
+
+	for i, arg := range fce.Args {
+		t := argTypes[i]
 
 		funSuper, innerErr := types.IsSuperTo(funType.ArgTypes[i], t)
 		if innerErr != nil {
@@ -60,8 +72,8 @@ func (fce *FunctionCallExpression) Type(tb types.TypeBindings) (types.Type, *mod
 	return funType.ReturnType, nil
 }
 
-func (fce *FunctionCallExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	f, err := fce.Function.Evaluate(bindings)
+func (fce *FunctionCallExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	f, err := fce.Function.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +88,7 @@ func (fce *FunctionCallExpression) Evaluate(bindings expressions.Bindings) (any,
 
 	argArray := make([]any, len(fce.Args))
 	for i, arg := range fce.Args {
-		val, err := arg.Evaluate(bindings)
+		val, err := arg.Evaluate(ctx, bindings)
 		if err != nil {
 			return nil, err
 		}
@@ -84,10 +96,14 @@ func (fce *FunctionCallExpression) Evaluate(bindings expressions.Bindings) (any,
 		argArray[i] = val
 	}
 
-	ret, innerErr := fun.Call(argArray)
+	if err := expressions.Step(ctx, fce.SourceLocation()); err != nil {
+		return nil, err
+	}
+
+	ret, innerErr := fun.Call(expressions.AtCallSite(ctx, fce.SourceLocation()), argArray)
 	if innerErr != nil {
 		msg := "in call to anonymous function"
-		if identifierExpression, ok := fce.Function.(*IdentifierExpression); ok {
+		if identifierExpression, ok := unwrapped(fce.Function).(*IdentifierExpression); ok {
 			msg = fmt.Sprintf("in call to function \"%s\"", identifierExpression.name)
 		}
 		return nil, &models.InterpreterError{
@@ -102,3 +118,11 @@ func (fce *FunctionCallExpression) Evaluate(bindings expressions.Bindings) (any,
 func (fce *FunctionCallExpression) SourceLocation() *models.SourceLocation {
 	return fce.loc
 }
+
+func (fce *FunctionCallExpression) String() string {
+	args := make([]string, 0, len(fce.Args))
+	for _, arg := range fce.Args {
+		args = append(args, fmt.Sprint(arg))
+	}
+	return fmt.Sprintf("%v(%s)", fce.Function, strings.Join(args, ", "))
+}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"context"
+
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
@@ -19,10 +21,17 @@ func (ie *IdentifierExpression) Type(tb types.TypeBindings) (types.Type, *models
 		}
 	}
 
+	if unavailable, ok := tb[ie.name].(types.UnavailableType); ok {
+		return nil, &models.InterpreterError{
+			Message:        unavailable.Reason,
+			SourceLocation: &ie.loc,
+		}
+	}
+
 	return tb[ie.name], nil
 }
 
-func (ie *IdentifierExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
+func (ie *IdentifierExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
 	ret, ok := map[string]any(bindings)[ie.name]
 	if !ok {
 		return nil, &models.InterpreterError{
@@ -37,3 +46,7 @@ func (ie *IdentifierExpression) Evaluate(bindings expressions.Bindings) (any, *m
 func (ie *IdentifierExpression) SourceLocation() *models.SourceLocation {
 	return &ie.loc
 }
+
+func (ie *IdentifierExpression) String() string {
+	return ie.name
+}
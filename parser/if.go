@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
@@ -42,8 +43,8 @@ func (ie *IfExpression) Type(tb types.TypeBindings) (types.Type, *models.Interpr
 	return types.Sum(thenType, elseType), nil
 }
 
-func (ie *IfExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	cond, err := ie.Condition.Evaluate(bindings)
+func (ie *IfExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	cond, err := ie.Condition.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -57,10 +58,10 @@ func (ie *IfExpression) Evaluate(bindings expressions.Bindings) (any, *models.In
 	}
 
 	if condBool {
-		return ie.Then.Evaluate(bindings)
+		return ie.Then.Evaluate(ctx, bindings)
 	}
 
-	return ie.Else.Evaluate(bindings)
+	return ie.Else.Evaluate(ctx, bindings)
 }
 
 func (ie *IfExpression) SourceLocation() *models.SourceLocation {
@@ -156,3 +157,7 @@ func parseIfExpression(toks *tokens.TokenStack) (exp expressions.Expression, err
 		loc:       beginLoc,
 	}, nil
 }
+
+func (ie *IfExpression) String() string {
+	return fmt.Sprintf("if %v then %v else %v", ie.Condition, ie.Then, ie.Else)
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/models/values"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+// InterpolationExpression is a string literal with embedded expressions,
+// e.g. "Some(${x})".  The values of the expressions, which may be of any
+// type, are formatted as by toString.
+type InterpolationExpression struct {
+	// Parts are the literal text and embedded expressions of the string,
+	// in order; text is held in string literals
+	Parts []expressions.Expression
+	loc   models.SourceLocation
+}
+
+func (ie *InterpolationExpression) Type(tb types.TypeBindings) (types.Type, *models.InterpreterError) {
+	for _, part := range ie.Parts {
+		if _, err := part.Type(tb); err != nil {
+			return nil, err
+		}
+	}
+
+	return types.PrimitiveTypeString, nil
+}
+
+func (ie *InterpolationExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	var sb strings.Builder
+	for _, part := range ie.Parts {
+		val, err := part.Evaluate(ctx, bindings)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(values.Format(val))
+
+		if innerErr := expressions.Allocate(ctx, sb.Len()); innerErr != nil {
+			return nil, &models.InterpreterError{
+				Message:        "in string interpolation",
+				Underlying:     innerErr,
+				SourceLocation: part.SourceLocation(),
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func (ie *InterpolationExpression) SourceLocation() *models.SourceLocation {
+	ret := ie.loc
+	return &ret
+}
+
+func (ie *InterpolationExpression) String() string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, part := range ie.Parts {
+		if lit, ok := part.(*LiteralExpression); ok {
+			if str, ok := lit.val.(string); ok {
+				quoted := strconv.Quote(str)
+				sb.WriteString(strings.ReplaceAll(quoted[1:len(quoted)-1], "$", `\$`))
+				continue
+			}
+		}
+		sb.WriteString("${")
+		sb.WriteString(part.(interface{ String() string }).String())
+		sb.WriteString("}")
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func parseInterpolation(tok tokens.Token) (expressions.Expression, *models.InterpreterError) {
+	ret := &InterpolationExpression{
+		Parts: make([]expressions.Expression, 0, len(tok.Parts)),
+		loc:   tok.SourceLocation,
+	}
+
+	for _, part := range tok.Parts {
+		if !part.IsExpression() {
+			ret.Parts = append(ret.Parts, &LiteralExpression{
+				val: part.Text,
+				loc: tok.SourceLocation,
+			})
+			continue
+		}
+
+		partLoc := part.SourceLocation
+		toks := tokens.NewTokenStack(part.Tokens, partLoc)
+		exp, err := ParseExpression(toks)
+		if err != nil {
+			return nil, &models.InterpreterError{
+				Message:        "in embedded expression",
+				SourceLocation: &partLoc,
+				Underlying:     err,
+			}
+		}
+
+		if extra, ok := toks.Peek(); ok {
+			return nil, &models.InterpreterError{
+				Message:        "unexpected token; expected end of embedded expression",
+				SourceLocation: &extra.SourceLocation,
+			}
+		}
+
+		ret.Parts = append(ret.Parts, exp)
+	}
+
+	return ret, nil
+}
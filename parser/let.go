@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
@@ -10,10 +12,15 @@ import (
 )
 
 type BindingExpression struct {
-	Identifier      string
+	Identifier string
+	// Pattern, if set, takes the value apart and binds its pieces, in
+	// which case Identifier is empty
+	Pattern         Pattern
 	ExpectedType    types.Type
 	ExpectedTypeLoc *models.SourceLocation
 	Expression      expressions.Expression
+	// Doc is the text of the doc comment on the binding, if any
+	Doc string
 }
 
 type LetExpression struct {
@@ -29,7 +36,7 @@ func (le *LetExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 	}
 
 	for _, bindingExp := range le.LetClauses {
-		if funcExp, ok := bindingExp.Expression.(*FunctionExpression); ok {
+		if funcExp, ok := bindingExp.Expression.(*FunctionExpression); ok && bindingExp.Pattern == nil {
 			typs := make([]types.Type, 0, len(funcExp.Args))
 			for _, arg := range funcExp.Args {
 				typs = append(typs, arg.Type)
@@ -42,6 +49,7 @@ func (le *LetExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 			return nil, err
 		}
 
+		bound := t
 		if bindingExp.ExpectedTypeLoc != nil {
 			isSuper, innerErr := types.IsSuperTo(bindingExp.ExpectedType, t)
 			if innerErr != nil {
@@ -75,16 +83,24 @@ func (le *LetExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 				}
 			}
 
-			newTB[bindingExp.Identifier] = bindingExp.ExpectedType
-		} else {
-			newTB[bindingExp.Identifier] = t
+			bound = bindingExp.ExpectedType
+		}
+
+		if bindingExp.Pattern == nil {
+			newTB[bindingExp.Identifier] = bound
+		} else if err := bindingExp.Pattern.bindTypes(bound, newTB); err != nil {
+			return nil, &models.InterpreterError{
+				Message:        "in let clause",
+				SourceLocation: le.SourceLocation(),
+				Underlying:     err,
+			}
 		}
 	}
 
 	return le.InClause.Type(newTB)
 }
 
-func (le *LetExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
+func (le *LetExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
 	newBindings := make(expressions.Bindings)
 	for k, v := range bindings {
 		newBindings[k] = v
@@ -92,19 +108,29 @@ func (le *LetExpression) Evaluate(bindings expressions.Bindings) (any, *models.I
 
 	for _, bindingExp := range le.LetClauses {
 		k, v := bindingExp.Identifier, bindingExp.Expression
-		val, err := v.Evaluate(newBindings)
+		val, err := v.Evaluate(ctx, newBindings)
 		if err != nil {
 			return nil, err
 		}
 
+		if bindingExp.Pattern != nil {
+			if err := bindingExp.Pattern.bind(val, newBindings); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		newBindings[k] = val
 
-		if funcVal, ok := val.(*FuncValue); ok {
-			funcVal.Bindings[k] = val
+		// only patch closures created by this binding; any other function
+		// value may already be shared, even with concurrently running tasks
+		if _, ok := v.(*FunctionExpression); ok {
+			if funcVal, ok := val.(*FuncValue); ok {
+				funcVal.Bindings[k] = val
+			}
 		}
 	}
 
-	return le.InClause.Evaluate(newBindings)
+	return le.InClause.Evaluate(ctx, newBindings)
 }
 
 func (le *LetExpression) SourceLocation() *models.SourceLocation {
@@ -129,6 +155,8 @@ func parseLetExpression(toks *tokens.TokenStack) (exp expressions.Expression, er
 			SourceLocation: &tok.SourceLocation,
 		}
 	}
+	// a doc comment on the first binding usually comes before "let"
+	letDoc := tok.Doc
 
 	tok, innerErr = toks.Pop()
 	if innerErr != nil {
@@ -145,18 +173,33 @@ func parseLetExpression(toks *tokens.TokenStack) (exp expressions.Expression, er
 
 	bindingExpressions := make([]BindingExpression, 0)
 	for {
-		if tok.Type != tokens.IDENTIFIER {
+		var pattern Pattern
+		identifier := tok.Value
+		if isPatternStart(tok) {
+			pattern, err = parsePattern(tok, toks)
+			if err != nil {
+				return nil, &models.InterpreterError{
+					Message:        "in let clause",
+					Underlying:     err,
+					SourceLocation: beginLoc,
+				}
+			}
+			identifier = ""
+		} else if tok.Type != tokens.IDENTIFIER {
 			return nil, &models.InterpreterError{
 				Message: "in let clause",
 				Underlying: &models.InterpreterError{
-					Message:        "unexpected token; expected identifier",
+					Message:        "unexpected token; expected identifier or pattern",
 					SourceLocation: &tok.SourceLocation,
 				},
 				SourceLocation: beginLoc,
 			}
 		}
-		identifier := tok.Value
 		identifierDeclLoc := tok.SourceLocation
+		doc := tok.Doc
+		if doc == "" {
+			doc, letDoc = letDoc, ""
+		}
 
 		var ok bool
 		tok, ok = toks.Peek()
@@ -214,7 +257,7 @@ func parseLetExpression(toks *tokens.TokenStack) (exp expressions.Expression, er
 			return nil, &models.InterpreterError{
 				Message: "in let clause",
 				Underlying: &models.InterpreterError{
-					Message:        "in binding clause for identifier \"" + identifier + "\"",
+					Message:        "in binding clause for " + describeBinding(identifier, pattern),
 					SourceLocation: &identifierDeclLoc,
 					Underlying: &models.InterpreterError{
 						Message:        "expected expression",
@@ -231,11 +274,16 @@ func parseLetExpression(toks *tokens.TokenStack) (exp expressions.Expression, er
 			return nil, err
 		}
 
+		if pattern == nil {
+			nameFunction(exp1, identifier)
+		}
 		bindingExpressions = append(bindingExpressions, BindingExpression{
 			Identifier:      identifier,
+			Pattern:         pattern,
 			Expression:      exp1,
 			ExpectedType:    typ,
 			ExpectedTypeLoc: typLoc,
+			Doc:             doc,
 		})
 
 		tok, innerErr = toks.Pop()
@@ -244,7 +292,7 @@ func parseLetExpression(toks *tokens.TokenStack) (exp expressions.Expression, er
 				Message:        "in let clause",
 				SourceLocation: beginLoc,
 				Underlying: &models.InterpreterError{
-					Message:        "after binding clause for identifier \"" + identifier + "\"",
+					Message:        "after binding clause for " + describeBinding(identifier, pattern),
 					SourceLocation: &identifierDeclLoc,
 					Underlying: &models.InterpreterError{
 						Message:        "expected \"in\" clause",
@@ -259,13 +307,14 @@ func parseLetExpression(toks *tokens.TokenStack) (exp expressions.Expression, er
 			break
 		}
 
+		commaLoc := tok.SourceLocation
 		if tok, innerErr = toks.Pop(); innerErr != nil {
 			return nil, &models.InterpreterError{
 				Message:        "in let clause",
 				SourceLocation: beginLoc,
 				Underlying: &models.InterpreterError{
 					Message:        "after comma",
-					SourceLocation: &tok.SourceLocation,
+					SourceLocation: &commaLoc,
 					Underlying: &models.InterpreterError{
 						Message:        "expected identifier for next binding",
 						SourceLocation: toks.CurrentSourceLocation(),
@@ -310,3 +359,18 @@ func parseLetExpression(toks *tokens.TokenStack) (exp expressions.Expression, er
 		InClause:   exp2,
 	}, nil
 }
+
+func (le *LetExpression) String() string {
+	clauses := make([]string, 0, len(le.LetClauses))
+	for _, bindingExp := range le.LetClauses {
+		clause := bindingExp.Identifier
+		if bindingExp.Pattern != nil {
+			clause = bindingExp.Pattern.String()
+		}
+		if bindingExp.ExpectedTypeLoc != nil {
+			clause += " " + bindingExp.ExpectedType.String()
+		}
+		clauses = append(clauses, fmt.Sprintf("%s = %v", clause, bindingExp.Expression))
+	}
+	return fmt.Sprintf("let %s in %v", strings.Join(clauses, ", "), le.InClause)
+}
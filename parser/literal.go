@@ -1,9 +1,15 @@
 package parser
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/models/values"
 )
 
 type LiteralExpression struct {
@@ -15,8 +21,10 @@ func (le *LiteralExpression) Type(tb types.TypeBindings) (types.Type, *models.In
 	switch le.val.(type) {
 	case bool:
 		return types.PrimitiveTypeBool, nil
-	case int:
+	case int, *big.Int:
 		return types.PrimitiveTypeInt, nil
+	case float64:
+		return types.PrimitiveTypeFloat, nil
 	case string:
 		return types.PrimitiveTypeString, nil
 	case struct{}:
@@ -26,13 +34,33 @@ func (le *LiteralExpression) Type(tb types.TypeBindings) (types.Type, *models.In
 	}
 }
 
-func (le *LiteralExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
+func (le *LiteralExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
 	if le == nil {
 		return nil, nil
 	}
+
+	if _, ok := le.val.(*big.Int); ok && expressions.IntOverflowMode(ctx) != expressions.IntOverflowPromote {
+		return nil, &models.InterpreterError{
+			Message:        fmt.Sprintf("integer literal %v overflows int", le.val),
+			SourceLocation: le.SourceLocation(),
+		}
+	}
 	return le.val, nil
 }
 
 func (le *LiteralExpression) SourceLocation() *models.SourceLocation {
 	return &le.loc
 }
+
+func (le *LiteralExpression) String() string {
+	switch val := le.val.(type) {
+	case string:
+		return strconv.Quote(val)
+	case float64:
+		return values.FormatFloat(val)
+	case struct{}:
+		return "unit"
+	default:
+		return fmt.Sprint(val)
+	}
+}
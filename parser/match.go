@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
@@ -44,8 +47,8 @@ func (me *MatchExpression) Type(tb types.TypeBindings) (types.Type, *models.Inte
 	return types.Sum(typs...), nil
 }
 
-func (me *MatchExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	onVal, err := me.On.Evaluate(bindings)
+func (me *MatchExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	onVal, err := me.On.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +78,7 @@ func (me *MatchExpression) Evaluate(bindings expressions.Bindings) (any, *models
 			}
 			newBindings[me.As] = onVal
 
-			return arm.Exp.Evaluate(newBindings)
+			return arm.Exp.Evaluate(ctx, newBindings)
 		}
 	}
 
@@ -188,3 +191,11 @@ func parseMatchExpression(toks *tokens.TokenStack) (exp expressions.Expression,
 
 	return ret, nil
 }
+
+func (me *MatchExpression) String() string {
+	str := fmt.Sprintf("match %s on %v", me.As, me.On)
+	for _, arm := range me.Arms {
+		str += fmt.Sprintf(" case %s %v", arm.Type, arm.Exp)
+	}
+	return str
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"container/list"
+	"math/big"
+	"sync"
+
+	"github.com/brandonksides/grundfunken/models/values"
+)
+
+// MemoOptions marks a function as memoized; its results are cached by the
+// structure of its arguments.  Memoization is only sound for functions
+// that are pure.
+type MemoOptions struct {
+	// Size bounds the number of results cached, evicting the least
+	// recently used; zero means the cache is unbounded
+	Size int
+}
+
+type memoCache struct {
+	size    int
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// most recently used at the front
+	order *list.List
+}
+
+type memoEntry struct {
+	key string
+	// args are kept so that the functions, channels and other values
+	// keyed by their address stay alive, and so that address is never
+	// reused by another value while the entry is cached
+	args []any
+	val  any
+}
+
+func newMemoCache(size int) *memoCache {
+	return &memoCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func memoKey(args []any) string {
+	return values.Key(args)
+}
+
+// sameIdentities reports whether the values compared by identity within
+// two values with the same key are the same values
+func sameIdentities(a, b any) bool {
+	switch a := a.(type) {
+	case []any:
+		bList, ok := b.([]any)
+		if !ok || len(bList) != len(a) {
+			return false
+		}
+		for i := range a {
+			if !sameIdentities(a[i], bList[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bObj, ok := b.(map[string]any)
+		if !ok || len(bObj) != len(a) {
+			return false
+		}
+		for k, v := range a {
+			if !sameIdentities(v, bObj[k]) {
+				return false
+			}
+		}
+		return true
+	case nil, struct{}, int, *big.Int, float64, bool, string:
+		// compared by the key itself
+		return true
+	default:
+		return a == b
+	}
+}
+
+func (mc *memoCache) get(key string, args []any) (any, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	elem, ok := mc.entries[key]
+	if !ok || !sameIdentities(elem.Value.(*memoEntry).args, args) {
+		return nil, false
+	}
+	mc.order.MoveToFront(elem)
+	return elem.Value.(*memoEntry).val, true
+}
+
+func (mc *memoCache) put(key string, args []any, val any) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if elem, ok := mc.entries[key]; ok {
+		entry := elem.Value.(*memoEntry)
+		entry.args = args
+		entry.val = val
+		mc.order.MoveToFront(elem)
+		return
+	}
+
+	mc.entries[key] = mc.order.PushFront(&memoEntry{key: key, args: args, val: val})
+	if mc.size > 0 && mc.order.Len() > mc.size {
+		oldest := mc.order.Back()
+		mc.order.Remove(oldest)
+		delete(mc.entries, oldest.Value.(*memoEntry).key)
+	}
+}
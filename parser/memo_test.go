@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+func TestMemoCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	mc := newMemoCache(2)
+	put := func(n int) {
+		args := []any{n}
+		mc.put(memoKey(args), args, n*n)
+	}
+	cached := func(n int) bool {
+		args := []any{n}
+		val, ok := mc.get(memoKey(args), args)
+		if ok && val != n*n {
+			t.Fatalf("cached result for %d is %v", n, val)
+		}
+		return ok
+	}
+
+	put(1)
+	put(2)
+	if !cached(1) {
+		t.Fatal("expected 1 to be cached")
+	}
+	// 2 is now the least recently used
+	put(3)
+	if cached(2) {
+		t.Error("expected 2 to be evicted")
+	}
+	if !cached(1) || !cached(3) {
+		t.Error("expected 1 and 3 to be cached")
+	}
+}
+
+func TestMemoCacheUnbounded(t *testing.T) {
+	mc := newMemoCache(0)
+	for n := 0; n < 100; n++ {
+		args := []any{n}
+		mc.put(memoKey(args), args, n)
+	}
+	for n := 0; n < 100; n++ {
+		args := []any{n}
+		if _, ok := mc.get(memoKey(args), args); !ok {
+			t.Errorf("expected %d to be cached", n)
+		}
+	}
+}
+
+func TestMemoCacheComparesByIdentity(t *testing.T) {
+	f, g := &FuncValue{}, &FuncValue{}
+	c1, c2 := make(chan any), make(chan any)
+
+	tests := []struct {
+		name   string
+		cached []any
+		lookup []any
+		found  bool
+	}{
+		{"same function", []any{f, 1}, []any{f, 1}, true},
+		{"other function", []any{f, 1}, []any{g, 1}, false},
+		{"same channel", []any{c1}, []any{c1}, true},
+		{"other channel", []any{c1}, []any{c2}, false},
+		{"function in list", []any{[]any{f}}, []any{[]any{g}}, false},
+		{"function in object", []any{map[string]any{"f": f}}, []any{map[string]any{"f": f}}, true},
+		{"equal structures", []any{[]any{1, "a"}, map[string]any{"x": true}}, []any{[]any{1, "a"}, map[string]any{"x": true}}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mc := newMemoCache(0)
+			mc.put(memoKey(test.cached), test.cached, "val")
+			if _, ok := mc.get(memoKey(test.lookup), test.lookup); ok != test.found {
+				t.Errorf("expected found to be %t", test.found)
+			}
+		})
+	}
+}
+
+func TestParseMemoOptions(t *testing.T) {
+	tests := []struct {
+		src  string
+		size int
+		err  string
+		// col is that of the offending token, counted from zero
+		col int
+	}{
+		{src: "func memo(n int) int n", size: 0},
+		{src: "func memo[10](n int) int n", size: 10},
+		{src: "func memo[0](n int) int n", err: "unexpected token; expected positive integer cache size", col: 10},
+		{src: "func memo[x](n int) int n", err: "unexpected token; expected positive integer cache size", col: 10},
+		{src: "func memo[10(n int) int n", err: "unexpected token; expected closing square bracket", col: 12},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			toks, err := tokens.Tokenize("memo.gf", []string{test.src})
+			if err != nil {
+				t.Fatal(err)
+			}
+			exp, err := ParseExpression(toks)
+			if test.err != "" {
+				if err == nil || err.Message != "in memo modifier" {
+					t.Fatalf("expected error in memo modifier; got %v", err)
+				}
+				underlying, ok := err.Underlying.(*models.InterpreterError)
+				if !ok || underlying.Message != test.err {
+					t.Fatalf("expected %q; got %v", test.err, err.Underlying)
+				}
+				if underlying.SourceLocation.ColumnNumber != test.col {
+					t.Errorf("expected error at column %d; got %d", test.col, underlying.SourceLocation.ColumnNumber)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fe, ok := exp.(*FunctionExpression)
+			if !ok || fe.Memo == nil {
+				t.Fatalf("expected memoized function; got %v", exp)
+			}
+			if fe.Memo.Size != test.size {
+				t.Errorf("expected cache size %d; got %d", test.size, fe.Memo.Size)
+			}
+		})
+	}
+}
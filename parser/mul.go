@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
@@ -21,66 +22,76 @@ func (me *MulExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 		return nil, err
 	}
 
-	if firstType != types.PrimitiveTypeInt {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to type %s", me.op.Value, firstType),
-			SourceLocation: me.first.SourceLocation(),
-		}
+	secondType, err := me.second.Type(tb)
+	if err != nil {
+		return nil, err
 	}
 
-	secondType, err := me.second.Type(tb)
+	typ, err := numericType(me.op.Value, me.first, me.second, firstType, secondType)
 	if err != nil {
 		return nil, err
 	}
 
-	if secondType != types.PrimitiveTypeInt {
+	if me.op.Type == tokens.PERCENT && typ != types.PrimitiveTypeInt {
 		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to type %s", me.op.Value, secondType),
-			SourceLocation: me.second.SourceLocation(),
+			Message:        fmt.Sprintf("operator '%s' cannot be applied to type %s", me.op.Value, typ),
+			SourceLocation: &me.op.SourceLocation,
 		}
 	}
 
-	return types.PrimitiveTypeInt, nil
+	return typ, nil
 }
 
-func (me *MulExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	v1, err := me.first.Evaluate(bindings)
+func (me *MulExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	v1, err := me.first.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
-	v1Muller, ok := v1.(int)
-	if !ok {
-		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to first operand %v", me.op.Value, v1),
-			SourceLocation: me.first.SourceLocation(),
-		}
+
+	v2, err := me.second.Evaluate(ctx, bindings)
+	if err != nil {
+		return nil, err
 	}
 
-	v2, err := me.second.Evaluate(bindings)
+	isFloat, err := numericOperands(me.op.Value, me.first, me.second, v1, v2)
 	if err != nil {
 		return nil, err
 	}
-	v2Muller, ok := v2.(int)
-	if !ok {
+
+	if isFloat {
+		f1, f2 := v1.(float64), v2.(float64)
+		if me.op.Type == tokens.SLASH && f2 == 0 {
+			return nil, &models.InterpreterError{
+				Message:        "division by zero",
+				SourceLocation: &me.op.SourceLocation,
+			}
+		}
+
+		switch me.op.Type {
+		case tokens.STAR:
+			return f1 * f2, nil
+		case tokens.SLASH:
+			return f1 / f2, nil
+		case tokens.PERCENT:
+			return nil, &models.InterpreterError{
+				Message:        fmt.Sprintf("operator '%s' cannot be applied to floats", me.op.Value),
+				SourceLocation: &me.op.SourceLocation,
+			}
+		}
 		return nil, &models.InterpreterError{
-			Message:        fmt.Sprintf("operator '%s' cannot be applied to second operand %v", me.op.Value, v2),
-			SourceLocation: me.second.SourceLocation(),
+			Message:        "invalid operator",
+			SourceLocation: &me.op.SourceLocation,
 		}
 	}
 
-	switch me.op.Type {
-	case tokens.STAR:
-		return v1Muller * v2Muller, nil
-	case tokens.SLASH:
-		return v1Muller / v2Muller, nil
-	case tokens.PERCENT:
-		return v1Muller % v2Muller, nil
-	default:
+	if (me.op.Type == tokens.SLASH || me.op.Type == tokens.PERCENT) && v2 == 0 {
 		return nil, &models.InterpreterError{
-			Message:        "invalid operator",
+			Message:        "division by zero",
 			SourceLocation: &me.op.SourceLocation,
 		}
 	}
+
+	return intArithmetic(ctx, me.op, v1, v2)
 }
 
 func (me *MulExpression) SourceLocation() *models.SourceLocation {
@@ -123,3 +134,7 @@ func foldMul(first expressions.Expression, toks *tokens.TokenStack) (exp express
 
 	return foldMul(withNext, toks)
 }
+
+func (me *MulExpression) String() string {
+	return fmt.Sprintf("(%v %s %v)", me.first, me.op.Value, me.second)
+}
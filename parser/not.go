@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
@@ -28,8 +31,8 @@ func (ne *NotExpression) Type(tb types.TypeBindings) (types.Type, *models.Interp
 	return types.PrimitiveTypeBool, nil
 }
 
-func (ne *NotExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	v, err := ne.Inner.Evaluate(bindings)
+func (ne *NotExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	v, err := ne.Inner.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -72,3 +75,7 @@ func parseNotExpression(toks *tokens.TokenStack) (exp expressions.Expression, er
 
 	return parseAtomic(toks)
 }
+
+func (ne *NotExpression) String() string {
+	return fmt.Sprintf("not %v", ne.Inner)
+}
@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/models/values"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+// ints and floats are never converted into one another implicitly: an
+// arithmetic or comparison operator takes two ints or two floats.  Operands
+// whose types are only known to be "int | float" are checked when the
+// program runs instead, and so give a result of type "int | float".
+var numberType = types.Sum(types.PrimitiveTypeInt, types.PrimitiveTypeFloat)
+
+// numericType returns the type of the operands of an arithmetic or
+// comparison operator
+func numericType(op string, first, second expressions.Expression, firstType, secondType types.Type) (types.Type, *models.InterpreterError) {
+	for _, operand := range []struct {
+		exp expressions.Expression
+		typ types.Type
+	}{{first, firstType}, {second, secondType}} {
+		isNumber, innerErr := types.IsSuperTo(numberType, operand.typ)
+		if innerErr != nil || !isNumber {
+			return nil, &models.InterpreterError{
+				Message:        fmt.Sprintf("operator '%s' cannot be applied to type %s", op, operand.typ),
+				SourceLocation: operand.exp.SourceLocation(),
+				Underlying:     innerErr,
+			}
+		}
+	}
+
+	if isPrimitiveNumber(firstType) && isPrimitiveNumber(secondType) {
+		if firstType == secondType {
+			return firstType, nil
+		}
+		return nil, &models.InterpreterError{
+			Message:        fmt.Sprintf("operator '%s' cannot be applied to %s and %s; convert one with toFloat or round", op, firstType, secondType),
+			SourceLocation: first.SourceLocation(),
+		}
+	}
+
+	return numberType, nil
+}
+
+func isPrimitiveNumber(t types.Type) bool {
+	return t == types.PrimitiveTypeInt || t == types.PrimitiveTypeFloat
+}
+
+// numericOperands checks that the values of the operands of an arithmetic
+// or comparison operator are both ints or both floats, reporting which
+func numericOperands(op string, first, second expressions.Expression, v1, v2 any) (isFloat bool, err *models.InterpreterError) {
+	switch v1.(type) {
+	case int, *big.Int:
+		if values.IsInt(v2) {
+			return false, nil
+		}
+	case float64:
+		if _, ok := v2.(float64); ok {
+			return true, nil
+		}
+	default:
+		return false, &models.InterpreterError{
+			Message:        fmt.Sprintf("operator '%s' cannot be applied to first operand %v", op, v1),
+			SourceLocation: first.SourceLocation(),
+		}
+	}
+
+	switch v2.(type) {
+	case int, *big.Int, float64:
+		t1, _ := types.TypeOf(v1)
+		t2, _ := types.TypeOf(v2)
+		return false, &models.InterpreterError{
+			Message:        fmt.Sprintf("operator '%s' cannot be applied to %s and %s", op, t1, t2),
+			SourceLocation: first.SourceLocation(),
+		}
+	default:
+		return false, &models.InterpreterError{
+			Message:        fmt.Sprintf("operator '%s' cannot be applied to second operand %v", op, v2),
+			SourceLocation: second.SourceLocation(),
+		}
+	}
+}
+
+// intArithmetic applies an arithmetic operator to two integers.  If the
+// result overflows an int, it is either an error or promoted to a
+// *big.Int, depending on the context.
+func intArithmetic(ctx context.Context, op tokens.Token, v1, v2 any) (any, *models.InterpreterError) {
+	i1, ok1 := v1.(int)
+	i2, ok2 := v2.(int)
+	if ok1 && ok2 {
+		if ret, ok := smallIntArithmetic(op.Type, i1, i2); ok {
+			return ret, nil
+		}
+	}
+
+	if expressions.IntOverflowMode(ctx) != expressions.IntOverflowPromote {
+		return nil, &models.InterpreterError{
+			Message:        fmt.Sprintf("integer overflow in %v %s %v", v1, op.Value, v2),
+			SourceLocation: &op.SourceLocation,
+		}
+	}
+
+	b1, b2 := values.BigInt(v1), values.BigInt(v2)
+	switch op.Type {
+	case tokens.PLUS:
+		return values.NormalizeInt(b1.Add(b1, b2)), nil
+	case tokens.MINUS:
+		return values.NormalizeInt(b1.Sub(b1, b2)), nil
+	case tokens.STAR:
+		return values.NormalizeInt(b1.Mul(b1, b2)), nil
+	case tokens.SLASH:
+		return values.NormalizeInt(b1.Quo(b1, b2)), nil
+	case tokens.PERCENT:
+		return values.NormalizeInt(b1.Rem(b1, b2)), nil
+	default:
+		return nil, &models.InterpreterError{
+			Message:        "invalid operator",
+			SourceLocation: &op.SourceLocation,
+		}
+	}
+}
+
+// smallIntArithmetic applies an arithmetic operator to two ints, reporting
+// false if the result doesn't fit in an int.  Division rounds towards
+// zero, for both ints and *big.Ints.
+func smallIntArithmetic(op tokens.TokenType, i1, i2 int) (int, bool) {
+	switch op {
+	case tokens.PLUS:
+		ret := i1 + i2
+		return ret, (ret > i1) == (i2 > 0)
+	case tokens.MINUS:
+		ret := i1 - i2
+		return ret, (ret < i1) == (i2 > 0)
+	case tokens.STAR:
+		if i1 == 0 || i2 == 0 {
+			return 0, true
+		}
+		if (i1 == -1 && i2 == math.MinInt) || (i2 == -1 && i1 == math.MinInt) {
+			return 0, false
+		}
+		ret := i1 * i2
+		return ret, ret/i2 == i1
+	case tokens.SLASH:
+		if i1 == math.MinInt && i2 == -1 {
+			return 0, false
+		}
+		return i1 / i2, true
+	case tokens.PERCENT:
+		if i2 == -1 {
+			return 0, true
+		}
+		return i1 % i2, true
+	default:
+		return 0, false
+	}
+}
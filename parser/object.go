@@ -1,6 +1,11 @@
 package parser
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/brandonksides/grundfunken/models"
 	"github.com/brandonksides/grundfunken/models/expressions"
 	"github.com/brandonksides/grundfunken/models/types"
@@ -9,7 +14,23 @@ import (
 
 type ObjectLiteralExpression struct {
 	Fields map[string]expressions.Expression
-	loc    *models.SourceLocation
+	// Spreads holds the objects whose fields are spread into the literal,
+	// as in "{...obj, x: 1}", in order
+	Spreads []ObjectSpread
+	// Docs holds the text of the doc comments on fields, by field name
+	Docs map[string]string
+	// order holds the names of the fields in the order they were written
+	order []string
+	loc   *models.SourceLocation
+}
+
+// ObjectSpread is an object whose fields are spread into an object
+// literal.  They replace the fields written before it, and are replaced
+// by those written after it.
+type ObjectSpread struct {
+	Expression expressions.Expression
+	// Index is the number of fields written before the spread
+	Index int
 }
 
 func (ole *ObjectLiteralExpression) Type(tb types.TypeBindings) (types.Type, *models.InterpreterError) {
@@ -21,10 +42,55 @@ func (ole *ObjectLiteralExpression) Type(tb types.TypeBindings) (types.Type, *mo
 		}
 		fieldTypes[key] = t
 	}
-	return types.Object(fieldTypes), nil
+	if len(ole.Spreads) == 0 {
+		return types.Object(fieldTypes), nil
+	}
+
+	retTypes := make(map[string]types.Type)
+	err := ole.inOrder(func(key string) {
+		retTypes[key] = fieldTypes[key]
+	}, func(spread ObjectSpread) *models.InterpreterError {
+		t, err := spread.Expression.Type(tb)
+		if err != nil {
+			return err
+		}
+		tObj, ok := t.(types.ObjectType)
+		if !ok {
+			return &models.InterpreterError{
+				Message:        fmt.Sprintf("cannot spread type %s into an object", t),
+				SourceLocation: spread.Expression.SourceLocation(),
+			}
+		}
+		for key, fieldType := range tObj.Fields {
+			retTypes[key] = fieldType
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return types.Object(retTypes), nil
 }
 
-func (ole *ObjectLiteralExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
+// inOrder calls field with the name of each field, and spread with each
+// spread, in the order they were written
+func (ole *ObjectLiteralExpression) inOrder(field func(string), spread func(ObjectSpread) *models.InterpreterError) *models.InterpreterError {
+	next := 0
+	for _, s := range ole.Spreads {
+		for ; next < s.Index; next++ {
+			field(ole.order[next])
+		}
+		if err := spread(s); err != nil {
+			return err
+		}
+	}
+	for ; next < len(ole.order); next++ {
+		field(ole.order[next])
+	}
+	return nil
+}
+
+func (ole *ObjectLiteralExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
 	newBindings := make(map[string]any)
 	for key, value := range bindings {
 		newBindings[key] = value
@@ -33,12 +99,45 @@ func (ole *ObjectLiteralExpression) Evaluate(bindings expressions.Bindings) (any
 	obj := make(map[string]any)
 	newBindings["this"] = obj
 
+	// with spreads, the fields are only put in the object once it is
+	// known which of them the spreads replace
+	vals := obj
+	if len(ole.Spreads) > 0 {
+		vals = make(map[string]any, len(ole.Fields))
+	}
+
 	for key, value := range ole.Fields {
-		val, err := value.Evaluate(newBindings)
+		val, err := value.Evaluate(ctx, newBindings)
 		if err != nil {
 			return nil, err
 		}
-		obj[key] = val
+		vals[key] = val
+	}
+	if len(ole.Spreads) == 0 {
+		return obj, nil
+	}
+
+	err := ole.inOrder(func(key string) {
+		obj[key] = vals[key]
+	}, func(spread ObjectSpread) *models.InterpreterError {
+		val, err := spread.Expression.Evaluate(ctx, bindings)
+		if err != nil {
+			return err
+		}
+		spreadObj, ok := val.(map[string]any)
+		if !ok {
+			return &models.InterpreterError{
+				Message:        fmt.Sprintf("expected object to spread; got %v", val),
+				SourceLocation: spread.Expression.SourceLocation(),
+			}
+		}
+		for key, fieldVal := range spreadObj {
+			obj[key] = fieldVal
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return obj, nil
 }
@@ -66,6 +165,9 @@ func parseObjectLiteralExpression(toks *tokens.TokenStack) (exp expressions.Expr
 	}
 
 	fields := make(map[string]expressions.Expression)
+	docs := make(map[string]string)
+	order := make([]string, 0)
+	var spreads []ObjectSpread
 	for {
 		tok, innerErr = toks.Pop()
 		if innerErr != nil {
@@ -81,77 +183,45 @@ func parseObjectLiteralExpression(toks *tokens.TokenStack) (exp expressions.Expr
 
 		if tok.Type == tokens.RIGHT_SQUIGGLY_BRACKET {
 			return &ObjectLiteralExpression{
-				Fields: fields,
-				loc:    beginLoc,
+				Fields:  fields,
+				Spreads: spreads,
+				Docs:    docs,
+				order:   order,
+				loc:     beginLoc,
 			}, nil
 		}
 
-		key := tok.Value
-		keyLoc := tok.SourceLocation
-
-		tok, innerErr = toks.Pop()
-		if innerErr != nil {
-			return nil, &models.InterpreterError{
-				Message:        "in object literal",
-				SourceLocation: beginLoc,
-				Underlying: &models.InterpreterError{
-					Message:        "to bind object field " + key,
-					SourceLocation: &keyLoc,
+		if tok.Type == tokens.ELLIPSIS {
+			spreadLoc := tok.SourceLocation
+			if _, ok := toks.Peek(); !ok {
+				return nil, &models.InterpreterError{
+					Message:        "in object literal expression",
+					SourceLocation: beginLoc,
 					Underlying: &models.InterpreterError{
-						Message:        "expected colon",
-						SourceLocation: toks.CurrentSourceLocation(),
-						Underlying:     innerErr,
+						Message:        "expected object to spread",
+						SourceLocation: &spreadLoc,
 					},
-				},
+				}
 			}
-		}
 
-		if tok.Type != tokens.COLON {
-			return nil, &models.InterpreterError{
-				Message:        "in object literal",
-				SourceLocation: beginLoc,
-				Underlying: &models.InterpreterError{
-					Message:        "to bind object field " + key,
-					SourceLocation: &keyLoc,
-					Underlying: &models.InterpreterError{
-						Message:        "unexpected token; expected colon",
-						SourceLocation: &tok.SourceLocation,
-						Underlying:     innerErr,
-					},
-				},
+			var spreadExp expressions.Expression
+			spreadExp, err = ParseExpression(toks)
+			if err != nil {
+				return nil, err
 			}
-		}
-		colLoc := tok.SourceLocation
-
-		_, ok := toks.Peek()
-		if !ok {
-			return nil, &models.InterpreterError{
-				Message:        "in object literal expression",
-				SourceLocation: beginLoc,
-				Underlying: &models.InterpreterError{
-					Message:        "to bind object field " + key,
-					SourceLocation: &keyLoc,
-					Underlying: &models.InterpreterError{
-						Message:        "after colon",
-						SourceLocation: &colLoc,
-						Underlying: &models.InterpreterError{
-							Message:        "expected expression",
-							SourceLocation: toks.CurrentSourceLocation(),
-							Underlying:     innerErr,
-						},
-					},
-				},
+			spreads = append(spreads, ObjectSpread{
+				Expression: spreadExp,
+				Index:      len(order),
+			})
+		} else {
+			err = parseObjectField(tok, toks, beginLoc, fields, docs)
+			if err != nil {
+				return nil, err
 			}
+			order = append(order, tok.Value)
 		}
 
-		var exp1 expressions.Expression
-		exp1, err = ParseExpression(toks)
-		if err != nil {
-			return nil, err
-		}
-
-		fields[key] = exp1
-
+		var ok bool
 		tok, ok = toks.Peek()
 		if !ok {
 			return nil, &models.InterpreterError{
@@ -185,7 +255,110 @@ func parseObjectLiteralExpression(toks *tokens.TokenStack) (exp expressions.Expr
 	}
 
 	return &ObjectLiteralExpression{
-		Fields: fields,
-		loc:    beginLoc,
+		Fields:  fields,
+		Spreads: spreads,
+		Docs:    docs,
+		order:   order,
+		loc:     beginLoc,
 	}, nil
 }
+
+// parseObjectField parses the field of an object literal whose name is
+// tok, which has already been popped from the stack, adding it to fields
+// and its doc comment to docs
+func parseObjectField(tok tokens.Token, toks *tokens.TokenStack, beginLoc *models.SourceLocation, fields map[string]expressions.Expression, docs map[string]string) *models.InterpreterError {
+	key := tok.Value
+	keyLoc := tok.SourceLocation
+	if tok.Doc != "" {
+		docs[key] = tok.Doc
+	}
+
+	tok, innerErr := toks.Pop()
+	if innerErr != nil {
+		return &models.InterpreterError{
+			Message:        "in object literal",
+			SourceLocation: beginLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "to bind object field " + key,
+				SourceLocation: &keyLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "expected colon",
+					SourceLocation: toks.CurrentSourceLocation(),
+					Underlying:     innerErr,
+				},
+			},
+		}
+	}
+
+	if tok.Type != tokens.COLON {
+		return &models.InterpreterError{
+			Message:        "in object literal",
+			SourceLocation: beginLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "to bind object field " + key,
+				SourceLocation: &keyLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "unexpected token; expected colon",
+					SourceLocation: &tok.SourceLocation,
+					Underlying:     innerErr,
+				},
+			},
+		}
+	}
+	colLoc := tok.SourceLocation
+
+	_, ok := toks.Peek()
+	if !ok {
+		return &models.InterpreterError{
+			Message:        "in object literal expression",
+			SourceLocation: beginLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "to bind object field " + key,
+				SourceLocation: &keyLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "after colon",
+					SourceLocation: &colLoc,
+					Underlying: &models.InterpreterError{
+						Message:        "expected expression",
+						SourceLocation: toks.CurrentSourceLocation(),
+						Underlying:     innerErr,
+					},
+				},
+			},
+		}
+	}
+
+	exp, err := ParseExpression(toks)
+	if err != nil {
+		return err
+	}
+
+	nameFunction(exp, key)
+	fields[key] = exp
+	return nil
+}
+
+func (ole *ObjectLiteralExpression) String() string {
+	if len(ole.Spreads) > 0 {
+		entries := make([]string, 0, len(ole.order)+len(ole.Spreads))
+		ole.inOrder(func(key string) {
+			entries = append(entries, fmt.Sprintf("%s: %v", key, ole.Fields[key]))
+		}, func(spread ObjectSpread) *models.InterpreterError {
+			entries = append(entries, fmt.Sprintf("...%v", spread.Expression))
+			return nil
+		})
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+
+	keys := make([]string, 0, len(ole.Fields))
+	for key := range ole.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, fmt.Sprintf("%s: %v", key, ole.Fields[key]))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
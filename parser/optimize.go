@@ -0,0 +1,350 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+)
+
+// Optimize returns an expression equivalent to the given one, with constant
+// subexpressions folded into literals and unreachable "if" and "match"
+// branches removed.  Since removing a branch also removes any type errors
+// in it, expressions should be type checked before they are optimized.
+//
+// Subexpressions that fail to evaluate, like "1 / 0", are left in place so
+// that their errors are still reported, at the same locations, when the
+// program runs.
+func Optimize(exp expressions.Expression) expressions.Expression {
+	return optimize(exp, scope{})
+}
+
+// scope holds the identifiers bound by the program around the expression
+// being optimized, so that calls to builtins are only folded where their
+// names haven't been shadowed
+type scope map[string]bool
+
+func (s scope) with(names ...string) scope {
+	ret := make(scope, len(s)+len(names))
+	for name := range s {
+		ret[name] = true
+	}
+	for _, name := range names {
+		ret[name] = true
+	}
+	return ret
+}
+
+func optimize(exp expressions.Expression, s scope) expressions.Expression {
+	switch exp := exp.(type) {
+	case *AddExpression:
+		ret := &AddExpression{
+			op:     exp.op,
+			first:  optimize(exp.first, s),
+			second: optimize(exp.second, s),
+		}
+		return foldConstant(ret, ret.first, ret.second)
+	case *MulExpression:
+		ret := &MulExpression{
+			op:     exp.op,
+			first:  optimize(exp.first, s),
+			second: optimize(exp.second, s),
+		}
+		return foldConstant(ret, ret.first, ret.second)
+	case *CmpExpression:
+		ret := &CmpExpression{
+			op:     exp.op,
+			first:  optimize(exp.first, s),
+			second: optimize(exp.second, s),
+		}
+		return foldConstant(ret, ret.first, ret.second)
+	case *EqExpression:
+		ret := &EqExpression{
+			Op:    exp.Op,
+			Left:  optimize(exp.Left, s),
+			Right: optimize(exp.Right, s),
+		}
+		return foldConstant(ret, ret.Left, ret.Right)
+	case *AndExpression:
+		left := optimize(exp.Left, s)
+		right := optimize(exp.Right, s)
+		if leftVal, ok := literalBool(left); ok {
+			if leftVal {
+				return right
+			}
+			return left
+		}
+		return &AndExpression{Left: left, Right: right}
+	case *OrExpression:
+		left := optimize(exp.Left, s)
+		right := optimize(exp.Right, s)
+		if leftVal, ok := literalBool(left); ok {
+			if leftVal {
+				return left
+			}
+			return right
+		}
+		return &OrExpression{Left: left, Right: right}
+	case *NotExpression:
+		ret := &NotExpression{
+			Inner: optimize(exp.Inner, s),
+			loc:   exp.loc,
+		}
+		return foldConstant(ret, ret.Inner)
+	case *IfExpression:
+		cond := optimize(exp.Condition, s)
+		if condVal, ok := literalBool(cond); ok {
+			if condVal {
+				return optimize(exp.Then, s)
+			}
+			return optimize(exp.Else, s)
+		}
+		return &IfExpression{
+			Condition: cond,
+			Then:      optimize(exp.Then, s),
+			Else:      optimize(exp.Else, s),
+			loc:       exp.loc,
+		}
+	case *MatchExpression:
+		return optimizeMatch(exp, s)
+	case *LetExpression:
+		inner := s
+		clauses := make([]BindingExpression, 0, len(exp.LetClauses))
+		for _, bindingExp := range exp.LetClauses {
+			// functions can refer to their own binding identifiers
+			if bindingExp.Pattern != nil {
+				inner = inner.with(bindingExp.Pattern.Names()...)
+			} else {
+				inner = inner.with(bindingExp.Identifier)
+			}
+			bindingExp.Expression = optimize(bindingExp.Expression, inner)
+			clauses = append(clauses, bindingExp)
+		}
+		return &LetExpression{
+			LetClauses: clauses,
+			InClause:   optimize(exp.InClause, inner),
+			loc:        exp.loc,
+		}
+	case *FunctionExpression:
+		names := make([]string, 0, len(exp.Args))
+		for i, arg := range exp.Args {
+			if pattern := exp.ArgPatterns[i]; pattern != nil {
+				names = append(names, pattern.Names()...)
+				continue
+			}
+			names = append(names, arg.Name)
+		}
+		ret := *exp
+		ret.body = optimize(exp.body, s.with(names...))
+		return &ret
+	case *FunctionCallExpression:
+		ret := &FunctionCallExpression{
+			Function: optimize(exp.Function, s),
+			Args:     make([]expressions.Expression, 0, len(exp.Args)),
+			loc:      exp.loc,
+		}
+		for _, arg := range exp.Args {
+			ret.Args = append(ret.Args, optimize(arg, s))
+		}
+		return foldBuiltinCall(ret, s)
+	case *ForExpression:
+		names := []string{exp.Identifier}
+		if exp.Pattern != nil {
+			names = exp.Pattern.Names()
+		}
+		return &ForExpression{
+			ForClause:   optimize(exp.ForClause, s.with(names...)),
+			Identifier:  exp.Identifier,
+			Pattern:     exp.Pattern,
+			InClause:    optimize(exp.InClause, s),
+			Parallel:    exp.Parallel,
+			seqElemType: exp.seqElemType,
+			loc:         exp.loc,
+		}
+	case *ObjectLiteralExpression:
+		inner := s.with("this")
+		fields := make(map[string]expressions.Expression, len(exp.Fields))
+		for key, value := range exp.Fields {
+			fields[key] = optimize(value, inner)
+		}
+		var spreads []ObjectSpread
+		for _, spread := range exp.Spreads {
+			// spreads are evaluated outside the object, without "this"
+			spreads = append(spreads, ObjectSpread{
+				Expression: optimize(spread.Expression, s),
+				Index:      spread.Index,
+			})
+		}
+		return &ObjectLiteralExpression{
+			Fields:  fields,
+			Spreads: spreads,
+			Docs:    exp.Docs,
+			order:   exp.order,
+			loc:     exp.loc,
+		}
+	case *ArrayLiteralExpression:
+		vals := make([]expressions.Expression, 0, len(exp.val))
+		for _, v := range exp.val {
+			vals = append(vals, optimize(v, s))
+		}
+		return &ArrayLiteralExpression{
+			elemType: exp.elemType,
+			val:      vals,
+			spreads:  exp.spreads,
+			loc:      exp.loc,
+		}
+	case *ArrayAccessExpression:
+		return &ArrayAccessExpression{
+			Array: optimize(exp.Array, s),
+			Index: optimize(exp.Index, s),
+			loc:   exp.loc,
+		}
+	case *ArraySliceExpression:
+		ret := &ArraySliceExpression{
+			Array: optimize(exp.Array, s),
+			loc:   exp.loc,
+		}
+		if exp.Begin != nil {
+			begin := optimize(*exp.Begin, s)
+			ret.Begin = &begin
+		}
+		if exp.End != nil {
+			end := optimize(*exp.End, s)
+			ret.End = &end
+		}
+		return ret
+	case *FieldAccessExpression:
+		return &FieldAccessExpression{
+			Object:   optimize(exp.Object, s),
+			Field:    exp.Field,
+			fieldLoc: exp.fieldLoc,
+		}
+	case *InterpolationExpression:
+		parts := make([]expressions.Expression, 0, len(exp.Parts))
+		for _, part := range exp.Parts {
+			parts = append(parts, optimize(part, s))
+		}
+		return &InterpolationExpression{
+			Parts: parts,
+			loc:   exp.loc,
+		}
+	case *WithExpression:
+		return &WithExpression{
+			Object:  optimize(exp.Object, s),
+			Update:  optimize(exp.Update, s),
+			withLoc: exp.withLoc,
+		}
+	case *AsExpression:
+		return &AsExpression{
+			exp:   optimize(exp.exp, s),
+			asLoc: exp.asLoc,
+			typ:   exp.typ,
+		}
+	default:
+		return exp
+	}
+}
+
+func optimizeMatch(exp *MatchExpression, s scope) expressions.Expression {
+	on := optimize(exp.On, s)
+	armScope := s.with(exp.As)
+
+	if lit, ok := on.(*LiteralExpression); ok {
+		if typ, err := types.TypeOf(lit.val); err == nil {
+			for _, arm := range exp.Arms {
+				armSuper, err := types.IsSuperTo(arm.Type, typ)
+				if err != nil {
+					break
+				}
+				if armSuper {
+					return &LetExpression{
+						LetClauses: []BindingExpression{{
+							Identifier:   exp.As,
+							ExpectedType: types.PrimitiveTypeAny,
+							Expression:   lit,
+						}},
+						InClause: optimize(arm.Exp, armScope),
+						loc:      exp.loc,
+					}
+				}
+			}
+		}
+	}
+
+	arms := make([]MatchArm, 0, len(exp.Arms))
+	for _, arm := range exp.Arms {
+		arms = append(arms, MatchArm{
+			Type: arm.Type,
+			Exp:  optimize(arm.Exp, armScope),
+		})
+	}
+	return &MatchExpression{
+		On:   on,
+		Arms: arms,
+		As:   exp.As,
+		loc:  exp.loc,
+	}
+}
+
+// foldBuiltinCall folds calls of pure builtins on literal arguments
+func foldBuiltinCall(call *FunctionCallExpression, s scope) expressions.Expression {
+	id, ok := call.Function.(*IdentifierExpression)
+	if !ok || s[id.name] {
+		return call
+	}
+
+	switch id.name {
+	case "concatStr":
+		if len(call.Args) != 2 {
+			return call
+		}
+		str1, ok1 := literalString(call.Args[0])
+		str2, ok2 := literalString(call.Args[1])
+		if ok1 && ok2 {
+			return &LiteralExpression{
+				val: str1 + str2,
+				loc: *call.SourceLocation(),
+			}
+		}
+	}
+
+	return call
+}
+
+// foldConstant replaces exp with a literal of its value if all of its
+// operands are literals and it evaluates without error
+func foldConstant(exp expressions.Expression, operands ...expressions.Expression) expressions.Expression {
+	for _, operand := range operands {
+		if _, ok := operand.(*LiteralExpression); !ok {
+			return exp
+		}
+	}
+
+	val, err := exp.Evaluate(context.Background(), expressions.Bindings{})
+	if err != nil {
+		return exp
+	}
+
+	return &LiteralExpression{
+		val: val,
+		loc: *exp.SourceLocation(),
+	}
+}
+
+func literalBool(exp expressions.Expression) (bool, bool) {
+	lit, ok := exp.(*LiteralExpression)
+	if !ok {
+		return false, false
+	}
+	b, ok := lit.val.(bool)
+	return b, ok
+}
+
+func literalString(exp expressions.Expression) (string, bool) {
+	lit, ok := exp.(*LiteralExpression)
+	if !ok {
+		return "", false
+	}
+	str, ok := lit.val.(string)
+	return str, ok
+}
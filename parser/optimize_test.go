@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+func parseSource(t *testing.T, src string) expressions.Expression {
+	t.Helper()
+
+	toks, err := tokens.Tokenize("optimize.gf", []string{src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err := ParseExpression(toks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exp
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"arithmetic", "1 + 2 * 3", "7"},
+		{"arithmetic with remainder", "10 % 3 - 7 / 2", "-2"},
+		{"partly constant arithmetic", "(1 + 2) * x", "(3 * x)"},
+		{"float arithmetic", "1.5 * 2.0", "3.0"},
+		{"comparison", "1 < 2", "true"},
+		{"equality", "\"a\" is \"a\"", "true"},
+		{"not", "not true", "false"},
+		{"and", "1 is 1 and 2 >= 3", "false"},
+		{"true and", "true and x", "x"},
+		{"false and", "false and x", "false"},
+		{"true or", "true or x", "true"},
+		{"false or", "false or x", "x"},
+		// the right operand may not be a bool at all, if the left fails
+		{"and true", "x and true", "(x and true)"},
+		{"if true", "if 1 < 2 then x else y", "x"},
+		{"if false", "if 1 > 2 then x else y", "y"},
+		{"if unknown", "if x then 1 + 1 else 2 + 2", "if x then 2 else 4"},
+		{"match first arm", "(match n on 1 case string x case int n + 1 case any y)", "let n = 1 in (n + 1)"},
+		{"match catch-all", "(match n on \"s\" case int x case any n)", "let n = \"s\" in n"},
+		{"match unknown", "(match n on x case string 1 + 1 case any 2)", "match n on x case string 2 case any 2"},
+		{"list", "[1 + 1, x]", "[2, x]"},
+		{"object", "{a: 1 + 1}", "{a: 2}"},
+		{"interpolation", "\"${1 + 1}\"", "\"${2}\""},
+		{"builtin", "concatStr(\"a\", \"b\")", "\"ab\""},
+		{
+			"builtin shadowed by let",
+			"let concatStr = func(a string, b string) string a in concatStr(\"a\", \"b\")",
+			"let concatStr = func(a string, b string) string a in concatStr(\"a\", \"b\")",
+		},
+		{
+			"builtin shadowed by argument",
+			"func(concatStr func(string, string) string) string concatStr(\"a\", \"b\")",
+			"func(concatStr func(string, string) string) string concatStr(\"a\", \"b\")",
+		},
+		{
+			"builtin shadowed by for",
+			"[concatStr(\"a\", \"b\") for concatStr in l]",
+			"[(concatStr(\"a\", \"b\") for concatStr in l)]",
+		},
+		{
+			// the closure can only see bindings before it
+			"builtin shadowed after closure",
+			"let f = func() string concatStr(\"a\", \"b\"), concatStr = 1 in f",
+			"let f = func() string \"ab\", concatStr = 1 in f",
+		},
+		{"division by zero", "1 / 0", "(1 / 0)"},
+		{"division by zero in operand", "x + 1 / 0", "(x + (1 / 0))"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := fmt.Sprint(Optimize(parseSource(t, test.src)))
+			if got != test.want {
+				t.Errorf("optimizing %s: want %s, got %s", test.src, test.want, got)
+			}
+		})
+	}
+}
+
+// TestOptimizeKeepsErrorLocations checks that a division by zero, which is
+// left in place, still fails at its operator
+func TestOptimizeKeepsErrorLocations(t *testing.T) {
+	exp := Optimize(parseSource(t, "2 * (1 / 0)"))
+
+	_, err := exp.Evaluate(context.Background(), expressions.Bindings{})
+	if err == nil {
+		t.Fatal("expected division by zero")
+	}
+	if err.Message != "division by zero" || err.SourceLocation.ColumnNumber != 7 {
+		t.Errorf("expected division by zero at column 7; got %s at column %d", err.Message, err.SourceLocation.ColumnNumber)
+	}
+}
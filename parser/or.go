@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/brandonksides/grundfunken/models"
@@ -42,8 +43,8 @@ func (oe *OrExpression) Type(tb types.TypeBindings) (types.Type, *models.Interpr
 	return types.PrimitiveTypeBool, nil
 }
 
-func (oe *OrExpression) Evaluate(bindings expressions.Bindings) (any, *models.InterpreterError) {
-	v1, err := oe.Left.Evaluate(bindings)
+func (oe *OrExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	v1, err := oe.Left.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +60,7 @@ func (oe *OrExpression) Evaluate(bindings expressions.Bindings) (any, *models.In
 		return true, nil
 	}
 
-	v2, err := oe.Right.Evaluate(bindings)
+	v2, err := oe.Right.Evaluate(ctx, bindings)
 	if err != nil {
 		return nil, err
 	}
@@ -112,3 +113,7 @@ func foldOr(first expressions.Expression, toks *tokens.TokenStack) (exp expressi
 
 	return foldOr(withNext, toks)
 }
+
+func (oe *OrExpression) String() string {
+	return fmt.Sprintf("(%v or %v)", oe.Left, oe.Right)
+}
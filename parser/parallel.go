@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+// ParallelOptions marks a for expression as parallel; its for clause is
+// evaluated for the elements of the list concurrently, though the results
+// keep the order of the list.
+type ParallelOptions struct {
+	// Workers bounds the number of elements evaluated at once; zero means
+	// one per available CPU
+	Workers int
+}
+
+func (po *ParallelOptions) workers() int {
+	if po.Workers > 0 {
+		return po.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// evaluateParallel evaluates the for clause for each element on a pool of
+// workers.  If any evaluations fail, the error of the failing element with
+// the lowest index is returned, so that the result doesn't depend on
+// scheduling.
+func (fe *ForExpression) evaluateParallel(ctx context.Context, bindings expressions.Bindings, list []any) (any, *models.InterpreterError) {
+	ret := make([]any, len(list))
+
+	var (
+		mu       sync.Mutex
+		errIndex = len(list)
+		firstErr *models.InterpreterError
+	)
+	// elements after a failed one are skipped; ones before it still have
+	// to be evaluated, since they might fail too
+	failedBefore := func(i int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return errIndex < i
+	}
+	fail := func(i int, err *models.InterpreterError) {
+		mu.Lock()
+		defer mu.Unlock()
+		if i < errIndex {
+			errIndex, firstErr = i, err
+		}
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < fe.Parallel.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			innerBindings := make(expressions.Bindings, len(bindings)+1)
+			for k, v := range bindings {
+				innerBindings[k] = v
+			}
+
+			for i := range indices {
+				if failedBefore(i) {
+					continue
+				}
+
+				retVal, err := fe.evaluateElement(ctx, innerBindings, list[i])
+				if err != nil {
+					fail(i, err)
+					continue
+				}
+				ret[i] = retVal
+			}
+		}()
+	}
+
+	for i := range list {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return ret, nil
+}
+
+// evaluateElement evaluates the for clause for one element on a worker.
+// A panic is returned as the error of the element, since nothing can
+// recover it once it unwinds past the worker.
+func (fe *ForExpression) evaluateElement(ctx context.Context, bindings expressions.Bindings, elem any) (ret any, err *models.InterpreterError) {
+	defer func() {
+		if r := recover(); r != nil {
+			ret, err = nil, &models.InterpreterError{
+				Message:        fmt.Sprintf("panic in parallel for: %v", r),
+				SourceLocation: fe.SourceLocation(),
+			}
+		}
+	}()
+
+	if err := expressions.Step(ctx, fe.SourceLocation()); err != nil {
+		return nil, err
+	}
+
+	if err := fe.bind(elem, bindings); err != nil {
+		return nil, err
+	}
+	return fe.ForClause.Evaluate(ctx, bindings)
+}
+
+func parseParallelOptions(toks *tokens.TokenStack) (*ParallelOptions, *models.InterpreterError) {
+	tok, ok := toks.Peek()
+	if !ok || tok.Type != tokens.IDENTIFIER || tok.Value != "parallel" {
+		return nil, nil
+	}
+	parallelLoc := tok.SourceLocation
+	toks.Pop()
+
+	parallel := &ParallelOptions{}
+
+	tok, ok = toks.Peek()
+	if !ok || tok.Type != tokens.LEFT_SQUARE_BRACKET {
+		return parallel, nil
+	}
+	toks.Pop()
+
+	tok, innerErr := toks.Pop()
+	if innerErr != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in parallel modifier",
+			SourceLocation: &parallelLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "expected number of workers",
+				SourceLocation: toks.CurrentSourceLocation(),
+				Underlying:     innerErr,
+			},
+		}
+	}
+
+	workers, convErr := strconv.Atoi(tok.Value)
+	if tok.Type != tokens.NUMBER || convErr != nil || workers <= 0 {
+		return nil, &models.InterpreterError{
+			Message:        "in parallel modifier",
+			SourceLocation: &parallelLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "unexpected token; expected positive integer number of workers",
+				SourceLocation: &tok.SourceLocation,
+			},
+		}
+	}
+	parallel.Workers = workers
+
+	tok, innerErr = toks.Pop()
+	if innerErr != nil {
+		return nil, &models.InterpreterError{
+			Message:        "in parallel modifier",
+			SourceLocation: &parallelLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "expected closing square bracket",
+				SourceLocation: toks.CurrentSourceLocation(),
+				Underlying:     innerErr,
+			},
+		}
+	}
+	if tok.Type != tokens.RIGHT_SQUARE_BRACKET {
+		return nil, &models.InterpreterError{
+			Message:        "in parallel modifier",
+			SourceLocation: &parallelLoc,
+			Underlying: &models.InterpreterError{
+				Message:        "unexpected token; expected closing square bracket",
+				SourceLocation: &tok.SourceLocation,
+			},
+		}
+	}
+
+	return parallel, nil
+}
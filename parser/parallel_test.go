@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+)
+
+// panickingExpression panics when x is one of the given values, and
+// otherwise evaluates to x
+type panickingExpression struct {
+	on map[int]bool
+}
+
+func (pe *panickingExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	x := bindings["x"].(int)
+	if pe.on[x] {
+		panic(fmt.Sprintf("element %d", x))
+	}
+	return x, nil
+}
+
+func (pe *panickingExpression) Type(tb types.TypeBindings) (types.Type, *models.InterpreterError) {
+	return types.PrimitiveTypeInt, nil
+}
+
+func (pe *panickingExpression) SourceLocation() *models.SourceLocation {
+	return nil
+}
+
+func TestParallelForRecoversPanics(t *testing.T) {
+	list := make([]any, 10)
+	for i := range list {
+		list[i] = i
+	}
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			fe := &ForExpression{
+				ForClause:  &panickingExpression{on: map[int]bool{3: true, 5: true}},
+				Identifier: "x",
+				Parallel:   &ParallelOptions{Workers: workers},
+				loc:        &models.SourceLocation{File: "parallel.gf"},
+			}
+
+			_, err := fe.evaluateParallel(context.Background(), make(expressions.Bindings), list)
+			// the panic of the lowest index is reported, as any other
+			// failure would be
+			if err == nil || err.Message != "panic in parallel for: element 3" {
+				t.Fatalf("expected the panic of element 3; got %v", err)
+			}
+			if err.SourceLocation != fe.loc {
+				t.Errorf("expected the location of the for expression; got %v", err.SourceLocation)
+			}
+		})
+	}
+}
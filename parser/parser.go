@@ -7,5 +7,5 @@ import (
 )
 
 func ParseExpression(toks *tokens.TokenStack) (exp expressions.Expression, err *models.InterpreterError) {
-	return parseOrExpression(toks)
+	return parsePipelineExpression(toks)
 }
@@ -0,0 +1,484 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+// Pattern is what a value is bound to in a let clause, for expression or
+// function argument: either an identifier, or an object or list pattern,
+// which takes the value apart and binds its pieces instead
+type Pattern interface {
+	// Names returns the identifiers the pattern binds, in the order they
+	// appear in it
+	Names() []string
+	// bindTypes binds the types of the identifiers in the pattern in tb,
+	// given the type of the value it takes apart
+	bindTypes(t types.Type, tb types.TypeBindings) *models.InterpreterError
+	// bind binds the identifiers in the pattern to the pieces of val
+	bind(val any, bindings expressions.Bindings) *models.InterpreterError
+	SourceLocation() *models.SourceLocation
+	String() string
+}
+
+type IdentifierPattern struct {
+	Name string
+	loc  models.SourceLocation
+}
+
+// ObjectPattern takes apart an object, as in "{x, y: [first, ...rest]}"
+type ObjectPattern struct {
+	Fields []FieldPattern
+	loc    models.SourceLocation
+}
+
+// FieldPattern matches a field of an object; a field without a pattern
+// of its own, like "x" in "{x}", is bound to the identifier of the same
+// name
+type FieldPattern struct {
+	Field   string
+	Pattern Pattern
+	loc     models.SourceLocation
+}
+
+// ListPattern takes apart a list, as in "[first, second, ...rest]".
+// Without a rest pattern, only lists of exactly as many elements match.
+type ListPattern struct {
+	Elements []Pattern
+	// Rest, if set, is bound to the elements after those matched by
+	// Elements
+	Rest Pattern
+	loc  models.SourceLocation
+}
+
+func (ip *IdentifierPattern) Names() []string {
+	return []string{ip.Name}
+}
+
+func (ip *IdentifierPattern) bindTypes(t types.Type, tb types.TypeBindings) *models.InterpreterError {
+	tb[ip.Name] = t
+	return nil
+}
+
+func (ip *IdentifierPattern) bind(val any, bindings expressions.Bindings) *models.InterpreterError {
+	bindings[ip.Name] = val
+	return nil
+}
+
+func (ip *IdentifierPattern) SourceLocation() *models.SourceLocation {
+	return &ip.loc
+}
+
+func (ip *IdentifierPattern) String() string {
+	return ip.Name
+}
+
+func (op *ObjectPattern) Names() []string {
+	names := make([]string, 0, len(op.Fields))
+	for _, field := range op.Fields {
+		names = append(names, field.Pattern.Names()...)
+	}
+	return names
+}
+
+func (op *ObjectPattern) bindTypes(t types.Type, tb types.TypeBindings) *models.InterpreterError {
+	if t == types.PrimitiveTypeAny {
+		// the value is only checked when the pattern is evaluated
+		for _, field := range op.Fields {
+			if err := field.Pattern.bindTypes(types.PrimitiveTypeAny, tb); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tObj, ok := t.(types.ObjectType)
+	if !ok {
+		return &models.InterpreterError{
+			Message:        fmt.Sprintf("cannot destructure type %s as an object", t),
+			SourceLocation: &op.loc,
+		}
+	}
+
+	for _, field := range op.Fields {
+		fieldType, ok := tObj.Fields[field.Field]
+		if !ok {
+			return &models.InterpreterError{
+				Message:        fmt.Sprintf("field %s not found on type %s", field.Field, t),
+				SourceLocation: &field.loc,
+			}
+		}
+		if err := field.Pattern.bindTypes(fieldType, tb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (op *ObjectPattern) bind(val any, bindings expressions.Bindings) *models.InterpreterError {
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return &models.InterpreterError{
+			Message:        fmt.Sprintf("expected object; got %v", val),
+			SourceLocation: &op.loc,
+		}
+	}
+
+	for _, field := range op.Fields {
+		fieldVal, ok := obj[field.Field]
+		if !ok {
+			return &models.InterpreterError{
+				Message:        fmt.Sprintf("field %s not found", field.Field),
+				SourceLocation: &field.loc,
+			}
+		}
+		if err := field.Pattern.bind(fieldVal, bindings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (op *ObjectPattern) SourceLocation() *models.SourceLocation {
+	return &op.loc
+}
+
+func (op *ObjectPattern) String() string {
+	fields := make([]string, 0, len(op.Fields))
+	for _, field := range op.Fields {
+		if ip, ok := field.Pattern.(*IdentifierPattern); ok && ip.Name == field.Field {
+			fields = append(fields, field.Field)
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s: %v", field.Field, field.Pattern))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+func (lp *ListPattern) Names() []string {
+	names := make([]string, 0, len(lp.Elements)+1)
+	for _, elem := range lp.Elements {
+		names = append(names, elem.Names()...)
+	}
+	if lp.Rest != nil {
+		names = append(names, lp.Rest.Names()...)
+	}
+	return names
+}
+
+func (lp *ListPattern) bindTypes(t types.Type, tb types.TypeBindings) *models.InterpreterError {
+	var elemType types.Type
+	switch t := t.(type) {
+	case types.ListType:
+		elemType = t.ElementType
+	default:
+		if t != types.PrimitiveTypeAny {
+			return &models.InterpreterError{
+				Message:        fmt.Sprintf("cannot destructure type %s as a list", t),
+				SourceLocation: &lp.loc,
+			}
+		}
+		// the value is only checked when the pattern is evaluated
+		elemType = types.PrimitiveTypeAny
+	}
+
+	for _, elem := range lp.Elements {
+		if err := elem.bindTypes(elemType, tb); err != nil {
+			return err
+		}
+	}
+	if lp.Rest != nil {
+		return lp.Rest.bindTypes(types.List(elemType), tb)
+	}
+	return nil
+}
+
+func (lp *ListPattern) bind(val any, bindings expressions.Bindings) *models.InterpreterError {
+	list, ok := val.([]any)
+	if !ok {
+		return &models.InterpreterError{
+			Message:        fmt.Sprintf("expected list; got %v", val),
+			SourceLocation: &lp.loc,
+		}
+	}
+
+	if lp.Rest == nil && len(list) != len(lp.Elements) {
+		return &models.InterpreterError{
+			Message:        fmt.Sprintf("expected list of %d elements; got %d", len(lp.Elements), len(list)),
+			SourceLocation: &lp.loc,
+		}
+	}
+	if len(list) < len(lp.Elements) {
+		return &models.InterpreterError{
+			Message:        fmt.Sprintf("expected list of at least %d elements; got %d", len(lp.Elements), len(list)),
+			SourceLocation: &lp.loc,
+		}
+	}
+
+	for i, elem := range lp.Elements {
+		if err := elem.bind(list[i], bindings); err != nil {
+			return err
+		}
+	}
+	if lp.Rest != nil {
+		return lp.Rest.bind(list[len(lp.Elements):], bindings)
+	}
+	return nil
+}
+
+func (lp *ListPattern) SourceLocation() *models.SourceLocation {
+	return &lp.loc
+}
+
+func (lp *ListPattern) String() string {
+	elems := make([]string, 0, len(lp.Elements)+1)
+	for _, elem := range lp.Elements {
+		elems = append(elems, elem.String())
+	}
+	if lp.Rest != nil {
+		elems = append(elems, "..."+lp.Rest.String())
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+// isPatternStart reports whether the token begins an object or list
+// pattern, rather than a lone identifier
+func isPatternStart(tok tokens.Token) bool {
+	return tok.Type == tokens.LEFT_SQUIGGLY_BRACKET || tok.Type == tokens.LEFT_SQUARE_BRACKET
+}
+
+// parsePattern parses the pattern beginning with tok, which has already
+// been popped from the stack
+func parsePattern(tok tokens.Token, toks *tokens.TokenStack) (Pattern, *models.InterpreterError) {
+	pattern, err := parseSubpattern(tok, toks)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range pattern.Names() {
+		if seen[name] {
+			return nil, &models.InterpreterError{
+				Message:        fmt.Sprintf("identifier \"%s\" is bound more than once in pattern", name),
+				SourceLocation: pattern.SourceLocation(),
+			}
+		}
+		seen[name] = true
+	}
+	return pattern, nil
+}
+
+func parseSubpattern(tok tokens.Token, toks *tokens.TokenStack) (Pattern, *models.InterpreterError) {
+	switch tok.Type {
+	case tokens.IDENTIFIER:
+		return &IdentifierPattern{
+			Name: tok.Value,
+			loc:  tok.SourceLocation,
+		}, nil
+	case tokens.LEFT_SQUIGGLY_BRACKET:
+		return parseObjectPattern(tok.SourceLocation, toks)
+	case tokens.LEFT_SQUARE_BRACKET:
+		return parseListPattern(tok.SourceLocation, toks)
+	default:
+		return nil, &models.InterpreterError{
+			Message:        "unexpected token; expected identifier, object pattern or list pattern",
+			SourceLocation: &tok.SourceLocation,
+		}
+	}
+}
+
+func parseObjectPattern(beginLoc models.SourceLocation, toks *tokens.TokenStack) (Pattern, *models.InterpreterError) {
+	ret := &ObjectPattern{
+		Fields: make([]FieldPattern, 0),
+		loc:    beginLoc,
+	}
+
+	for {
+		tok, innerErr := toks.Pop()
+		if innerErr != nil {
+			return nil, &models.InterpreterError{
+				Message:        "in object pattern",
+				SourceLocation: &beginLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "expected field name or closing squiggly bracket",
+					SourceLocation: toks.CurrentSourceLocation(),
+					Underlying:     innerErr,
+				},
+			}
+		}
+		if tok.Type == tokens.RIGHT_SQUIGGLY_BRACKET {
+			return ret, nil
+		}
+		if tok.Type != tokens.IDENTIFIER {
+			return nil, &models.InterpreterError{
+				Message:        "in object pattern",
+				SourceLocation: &beginLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "unexpected token; expected field name",
+					SourceLocation: &tok.SourceLocation,
+				},
+			}
+		}
+
+		field := FieldPattern{
+			Field: tok.Value,
+			Pattern: &IdentifierPattern{
+				Name: tok.Value,
+				loc:  tok.SourceLocation,
+			},
+			loc: tok.SourceLocation,
+		}
+
+		if next, ok := toks.Peek(); ok && next.Type == tokens.COLON {
+			toks.Pop()
+			tok, innerErr = toks.Pop()
+			if innerErr != nil {
+				return nil, &models.InterpreterError{
+					Message:        "in object pattern",
+					SourceLocation: &beginLoc,
+					Underlying: &models.InterpreterError{
+						Message:        "expected pattern for field " + field.Field,
+						SourceLocation: toks.CurrentSourceLocation(),
+						Underlying:     innerErr,
+					},
+				}
+			}
+			var err *models.InterpreterError
+			field.Pattern, err = parseSubpattern(tok, toks)
+			if err != nil {
+				return nil, err
+			}
+		}
+		ret.Fields = append(ret.Fields, field)
+
+		tok, innerErr = toks.Pop()
+		if innerErr != nil {
+			return nil, &models.InterpreterError{
+				Message:        "in object pattern",
+				SourceLocation: &beginLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "expected comma or closing squiggly bracket",
+					SourceLocation: toks.CurrentSourceLocation(),
+					Underlying:     innerErr,
+				},
+			}
+		}
+		if tok.Type == tokens.RIGHT_SQUIGGLY_BRACKET {
+			return ret, nil
+		}
+		if tok.Type != tokens.COMMA {
+			return nil, &models.InterpreterError{
+				Message:        "in object pattern",
+				SourceLocation: &beginLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "unexpected token; expected comma or closing squiggly bracket",
+					SourceLocation: &tok.SourceLocation,
+				},
+			}
+		}
+	}
+}
+
+func parseListPattern(beginLoc models.SourceLocation, toks *tokens.TokenStack) (Pattern, *models.InterpreterError) {
+	ret := &ListPattern{
+		Elements: make([]Pattern, 0),
+		loc:      beginLoc,
+	}
+
+	for {
+		tok, innerErr := toks.Pop()
+		if innerErr != nil {
+			return nil, &models.InterpreterError{
+				Message:        "in list pattern",
+				SourceLocation: &beginLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "expected pattern or closing square bracket",
+					SourceLocation: toks.CurrentSourceLocation(),
+					Underlying:     innerErr,
+				},
+			}
+		}
+		if tok.Type == tokens.RIGHT_SQUARE_BRACKET {
+			return ret, nil
+		}
+
+		rest := tok.Type == tokens.ELLIPSIS
+		if rest {
+			tok, innerErr = toks.Pop()
+			if innerErr != nil {
+				return nil, &models.InterpreterError{
+					Message:        "in list pattern",
+					SourceLocation: &beginLoc,
+					Underlying: &models.InterpreterError{
+						Message:        "expected pattern for the rest of the list",
+						SourceLocation: toks.CurrentSourceLocation(),
+						Underlying:     innerErr,
+					},
+				}
+			}
+		}
+
+		elem, err := parseSubpattern(tok, toks)
+		if err != nil {
+			return nil, err
+		}
+
+		tok, innerErr = toks.Pop()
+		if innerErr != nil {
+			return nil, &models.InterpreterError{
+				Message:        "in list pattern",
+				SourceLocation: &beginLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "expected comma or closing square bracket",
+					SourceLocation: toks.CurrentSourceLocation(),
+					Underlying:     innerErr,
+				},
+			}
+		}
+
+		if rest {
+			// the rest of the list must come last
+			if tok.Type != tokens.RIGHT_SQUARE_BRACKET {
+				return nil, &models.InterpreterError{
+					Message:        "in list pattern",
+					SourceLocation: &beginLoc,
+					Underlying: &models.InterpreterError{
+						Message:        "unexpected token; expected closing square bracket after the rest of the list",
+						SourceLocation: &tok.SourceLocation,
+					},
+				}
+			}
+			ret.Rest = elem
+			return ret, nil
+		}
+
+		ret.Elements = append(ret.Elements, elem)
+		if tok.Type == tokens.RIGHT_SQUARE_BRACKET {
+			return ret, nil
+		}
+		if tok.Type != tokens.COMMA {
+			return nil, &models.InterpreterError{
+				Message:        "in list pattern",
+				SourceLocation: &beginLoc,
+				Underlying: &models.InterpreterError{
+					Message:        "unexpected token; expected comma or closing square bracket",
+					SourceLocation: &tok.SourceLocation,
+				},
+			}
+		}
+	}
+}
+
+// describeBinding names what a clause binds in error messages: an
+// identifier, or a pattern
+func describeBinding(identifier string, pattern Pattern) string {
+	if pattern != nil {
+		return fmt.Sprintf("pattern \"%v\"", pattern)
+	}
+	return fmt.Sprintf("identifier \"%s\"", identifier)
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/tokens"
+)
+
+// placeholder is the identifier that marks where the value on the left of
+// "|>" goes among the arguments of the call on its right
+const placeholder = "_"
+
+func parsePipelineExpression(toks *tokens.TokenStack) (exp expressions.Expression, err *models.InterpreterError) {
+	left, err := parseOrExpression(toks)
+	if err != nil {
+		return nil, err
+	}
+
+	return foldPipeline(left, toks)
+}
+
+// foldPipeline folds "x |> f" into the call f(x), and "x |> f(a, _)" into
+// the call f(a, x), so that pipelines are checked and evaluated as the
+// calls they stand for
+func foldPipeline(first expressions.Expression, toks *tokens.TokenStack) (exp expressions.Expression, err *models.InterpreterError) {
+	tok, ok := toks.Peek()
+	if !ok || tok.Type != tokens.PIPELINE {
+		return first, nil
+	}
+	if first == nil {
+		return nil, &models.InterpreterError{
+			Message:        "expected expression",
+			SourceLocation: &tok.SourceLocation,
+		}
+	}
+	toks.Pop()
+
+	next, err := parseOrExpression(toks)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		return nil, &models.InterpreterError{
+			Message:        "expected function after \"|>\"",
+			SourceLocation: toks.CurrentSourceLocation(),
+		}
+	}
+
+	withNext, err := pipeInto(first, next, tok.SourceLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	return foldPipeline(withNext, toks)
+}
+
+// pipeInto returns the call that passes arg to the function on the right
+// of a "|>" at the given location
+func pipeInto(arg expressions.Expression, next expressions.Expression, loc models.SourceLocation) (expressions.Expression, *models.InterpreterError) {
+	call, ok := next.(*FunctionCallExpression)
+	if !ok {
+		return &FunctionCallExpression{
+			Function: next,
+			Args:     []expressions.Expression{arg},
+			loc:      &loc,
+		}, nil
+	}
+
+	var found *models.SourceLocation
+	args := make([]expressions.Expression, 0, len(call.Args))
+	for _, a := range call.Args {
+		if id, ok := a.(*IdentifierExpression); !ok || id.name != placeholder {
+			args = append(args, a)
+			continue
+		}
+		if found != nil {
+			// substituting the value twice would evaluate it twice
+			return nil, &models.InterpreterError{
+				Message:        "placeholder \"_\" may only be used once in a call after \"|>\"",
+				SourceLocation: a.SourceLocation(),
+			}
+		}
+		found = a.SourceLocation()
+		args = append(args, arg)
+	}
+	if found == nil {
+		// without a placeholder, the call is the function to pass the
+		// value to
+		return &FunctionCallExpression{
+			Function: next,
+			Args:     []expressions.Expression{arg},
+			loc:      &loc,
+		}, nil
+	}
+
+	return &FunctionCallExpression{
+		Function: call.Function,
+		Args:     args,
+		loc:      call.loc,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"reflect"
+
+	"github.com/brandonksides/grundfunken/models/expressions"
+)
+
+// Snapshot returns a copy of v that is safe to hand to another task.
+//
+// Values are immutable once built, but an object literal is still being
+// filled in while its fields are evaluated, and any closure created in one
+// of those fields sees it through "this".  Snapshot copies objects, and the
+// bindings of closures, so that the copy is unaffected by writes that happen
+// after it is taken.  Lists are only copied if one of their elements is.
+func Snapshot(v any) any {
+	return snapshot(v, make(map[uintptr]any))
+}
+
+// seen maps objects and closures already copied to their copies, since
+// objects and their methods refer to each other through "this"
+func snapshot(v any, seen map[uintptr]any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		ptr := reflect.ValueOf(v).Pointer()
+		if ret, ok := seen[ptr]; ok {
+			return ret
+		}
+
+		ret := make(map[string]any, len(v))
+		seen[ptr] = ret
+		for k, field := range v {
+			ret[k] = snapshot(field, seen)
+		}
+		return ret
+	case []any:
+		var ret []any
+		for i, elem := range v {
+			elemCopy := snapshot(elem, seen)
+			if ret == nil && !same(elemCopy, elem) {
+				ret = make([]any, len(v))
+				copy(ret, v[:i])
+			}
+			if ret != nil {
+				ret[i] = elemCopy
+			}
+		}
+		if ret == nil {
+			return v
+		}
+		return ret
+	case *FuncValue:
+		ptr := reflect.ValueOf(v).Pointer()
+		if ret, ok := seen[ptr]; ok {
+			return ret
+		}
+
+		ret := &FuncValue{
+			Bindings: make(expressions.Bindings, len(v.Bindings)),
+			Exp:      v.Exp,
+			cache:    v.cache,
+		}
+		seen[ptr] = ret
+		for k, bound := range v.Bindings {
+			ret.Bindings[k] = snapshot(bound, seen)
+		}
+		return ret
+	default:
+		return v
+	}
+}
+
+// same reports whether snapshot returned the value it was given
+func same(copied any, orig any) bool {
+	switch orig.(type) {
+	case map[string]any, []any, *FuncValue:
+		return reflect.ValueOf(copied).Pointer() == reflect.ValueOf(orig).Pointer()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+)
+
+// AddStopPoints returns an expression equivalent to the given one that
+// notifies the stop hook of its context, through expressions.Stop, before
+// evaluating any subexpression that starts a new line, the body of any
+// function, or the expression as a whole.  Debuggers pause at them.
+func AddStopPoints(exp expressions.Expression) expressions.Expression {
+	return addStopPoints(exp, -1)
+}
+
+func addStopPoints(exp expressions.Expression, parentLine int) expressions.Expression {
+	loc := exp.SourceLocation()
+	if loc == nil {
+		return mapChildren(exp, func(child expressions.Expression) expressions.Expression {
+			return addStopPoints(child, parentLine)
+		})
+	}
+
+	ret := mapChildren(exp, func(child expressions.Expression) expressions.Expression {
+		return addStopPoints(child, loc.LineNumber)
+	})
+
+	// a let binding only patches the closures of its own function
+	// expressions, so they must stay function expressions; their bodies
+	// have stop points instead
+	if funcExp, ok := ret.(*FunctionExpression); ok {
+		if _, ok := funcExp.body.(*stopPointExpression); !ok && funcExp.body.SourceLocation() != nil {
+			funcExp.body = &stopPointExpression{Expression: funcExp.body}
+		}
+		return funcExp
+	}
+
+	if loc.LineNumber == parentLine {
+		return ret
+	}
+	return &stopPointExpression{Expression: ret}
+}
+
+// stopPointExpression notifies the stop hook of its context before
+// evaluating the expression it wraps, which it otherwise behaves exactly
+// as
+type stopPointExpression struct {
+	expressions.Expression
+}
+
+func (spe *stopPointExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	expressions.Stop(ctx, spe.SourceLocation(), bindings)
+	return spe.Expression.Evaluate(ctx, bindings)
+}
+
+func (spe *stopPointExpression) String() string {
+	return fmt.Sprint(spe.Expression)
+}
@@ -138,7 +138,28 @@ func parseAtomicType(toks *tokens.TokenStack) (types.Type, error) {
 			}
 		}
 
+		if tok.Value == "seq" {
+			elemType, err := parseAtomicType(toks)
+			if err != nil {
+				return nil, err
+			}
+			return types.Seq(elemType), nil
+		} else if tok.Value == "future" {
+			elemType, err := parseAtomicType(toks)
+			if err != nil {
+				return nil, err
+			}
+			return types.Future(elemType), nil
+		}
+
 		return types.ParsePrimitive(tok.Value), nil
+	case tokens.CHAN:
+		toks.Pop()
+		elemType, err := parseAtomicType(toks)
+		if err != nil {
+			return nil, err
+		}
+		return types.Chan(elemType), nil
 	case tokens.LEFT_SQUARE_BRACKET:
 		toks.Pop()
 		typ, err := parseType(toks)
@@ -0,0 +1,229 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/brandonksides/grundfunken/models/expressions"
+)
+
+// unwrapped returns the expression wrapped by any counters or stop points
+// around exp
+func unwrapped(exp expressions.Expression) expressions.Expression {
+	for {
+		switch wrapper := exp.(type) {
+		case *countedExpression:
+			exp = wrapper.Expression
+		case *stopPointExpression:
+			exp = wrapper.Expression
+		default:
+			return exp
+		}
+	}
+}
+
+// mapChildren returns a copy of exp with f applied to each of its
+// children, in the order they appear in the source, or exp itself if it
+// has none.  The fields of objects are visited in order of name, after any
+// objects spread into them, and the literal text between the embedded
+// expressions of a string is left alone.  Counters and stop points are
+// kept around the copy of the expression they wrap.
+func mapChildren(exp expressions.Expression, f func(expressions.Expression) expressions.Expression) expressions.Expression {
+	switch exp := exp.(type) {
+	case *countedExpression:
+		return &countedExpression{
+			Expression: mapChildren(exp.Expression, f),
+			counter:    exp.counter,
+		}
+	case *stopPointExpression:
+		return &stopPointExpression{
+			Expression: mapChildren(exp.Expression, f),
+		}
+	case *AddExpression:
+		return &AddExpression{
+			op:     exp.op,
+			first:  f(exp.first),
+			second: f(exp.second),
+		}
+	case *MulExpression:
+		return &MulExpression{
+			op:     exp.op,
+			first:  f(exp.first),
+			second: f(exp.second),
+		}
+	case *CmpExpression:
+		return &CmpExpression{
+			op:     exp.op,
+			first:  f(exp.first),
+			second: f(exp.second),
+		}
+	case *EqExpression:
+		return &EqExpression{
+			Op:    exp.Op,
+			Left:  f(exp.Left),
+			Right: f(exp.Right),
+		}
+	case *AndExpression:
+		return &AndExpression{
+			Left:  f(exp.Left),
+			Right: f(exp.Right),
+		}
+	case *OrExpression:
+		return &OrExpression{
+			Left:  f(exp.Left),
+			Right: f(exp.Right),
+		}
+	case *NotExpression:
+		return &NotExpression{
+			Inner: f(exp.Inner),
+			loc:   exp.loc,
+		}
+	case *IfExpression:
+		return &IfExpression{
+			Condition: f(exp.Condition),
+			Then:      f(exp.Then),
+			Else:      f(exp.Else),
+			loc:       exp.loc,
+		}
+	case *MatchExpression:
+		on := f(exp.On)
+		arms := make([]MatchArm, 0, len(exp.Arms))
+		for _, arm := range exp.Arms {
+			arms = append(arms, MatchArm{
+				Type: arm.Type,
+				Exp:  f(arm.Exp),
+			})
+		}
+		return &MatchExpression{
+			On:   on,
+			Arms: arms,
+			As:   exp.As,
+			loc:  exp.loc,
+		}
+	case *LetExpression:
+		clauses := make([]BindingExpression, 0, len(exp.LetClauses))
+		for _, bindingExp := range exp.LetClauses {
+			bindingExp.Expression = f(bindingExp.Expression)
+			clauses = append(clauses, bindingExp)
+		}
+		return &LetExpression{
+			LetClauses: clauses,
+			InClause:   f(exp.InClause),
+			loc:        exp.loc,
+		}
+	case *FunctionExpression:
+		ret := *exp
+		ret.body = f(exp.body)
+		return &ret
+	case *FunctionCallExpression:
+		ret := &FunctionCallExpression{
+			Function: f(exp.Function),
+			Args:     make([]expressions.Expression, 0, len(exp.Args)),
+			loc:      exp.loc,
+		}
+		for _, arg := range exp.Args {
+			ret.Args = append(ret.Args, f(arg))
+		}
+		return ret
+	case *ForExpression:
+		// the for clause comes first in the source, but is evaluated last
+		forClause := f(exp.ForClause)
+		return &ForExpression{
+			ForClause:   forClause,
+			Identifier:  exp.Identifier,
+			Pattern:     exp.Pattern,
+			InClause:    f(exp.InClause),
+			Parallel:    exp.Parallel,
+			seqElemType: exp.seqElemType,
+			loc:         exp.loc,
+		}
+	case *ObjectLiteralExpression:
+		keys := make([]string, 0, len(exp.Fields))
+		for key := range exp.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var spreads []ObjectSpread
+		for _, spread := range exp.Spreads {
+			spreads = append(spreads, ObjectSpread{
+				Expression: f(spread.Expression),
+				Index:      spread.Index,
+			})
+		}
+		fields := make(map[string]expressions.Expression, len(exp.Fields))
+		for _, key := range keys {
+			fields[key] = f(exp.Fields[key])
+		}
+		return &ObjectLiteralExpression{
+			Fields:  fields,
+			Spreads: spreads,
+			Docs:    exp.Docs,
+			order:   exp.order,
+			loc:     exp.loc,
+		}
+	case *ArrayLiteralExpression:
+		vals := make([]expressions.Expression, 0, len(exp.val))
+		for _, v := range exp.val {
+			vals = append(vals, f(v))
+		}
+		return &ArrayLiteralExpression{
+			elemType: exp.elemType,
+			val:      vals,
+			spreads:  exp.spreads,
+			loc:      exp.loc,
+		}
+	case *ArrayAccessExpression:
+		return &ArrayAccessExpression{
+			Array: f(exp.Array),
+			Index: f(exp.Index),
+			loc:   exp.loc,
+		}
+	case *ArraySliceExpression:
+		ret := &ArraySliceExpression{
+			Array: f(exp.Array),
+			loc:   exp.loc,
+		}
+		if exp.Begin != nil {
+			begin := f(*exp.Begin)
+			ret.Begin = &begin
+		}
+		if exp.End != nil {
+			end := f(*exp.End)
+			ret.End = &end
+		}
+		return ret
+	case *FieldAccessExpression:
+		return &FieldAccessExpression{
+			Object:   f(exp.Object),
+			Field:    exp.Field,
+			fieldLoc: exp.fieldLoc,
+		}
+	case *InterpolationExpression:
+		parts := make([]expressions.Expression, 0, len(exp.Parts))
+		for _, part := range exp.Parts {
+			if _, ok := part.(*LiteralExpression); ok {
+				parts = append(parts, part)
+				continue
+			}
+			parts = append(parts, f(part))
+		}
+		return &InterpolationExpression{
+			Parts: parts,
+			loc:   exp.loc,
+		}
+	case *WithExpression:
+		return &WithExpression{
+			Object:  f(exp.Object),
+			Update:  f(exp.Update),
+			withLoc: exp.withLoc,
+		}
+	case *AsExpression:
+		return &AsExpression{
+			exp:   f(exp.exp),
+			asLoc: exp.asLoc,
+			typ:   exp.typ,
+		}
+	default:
+		return exp
+	}
+}
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/brandonksides/grundfunken/models"
+	"github.com/brandonksides/grundfunken/models/expressions"
+	"github.com/brandonksides/grundfunken/models/types"
+)
+
+// WithExpression is a copy of an object with some of its fields replaced,
+// as in "p with {x: 1}".  Unlike spreading the object into a literal, it
+// cannot add fields or change their types.
+type WithExpression struct {
+	Object  expressions.Expression
+	Update  expressions.Expression
+	withLoc models.SourceLocation
+}
+
+func (we *WithExpression) Type(tb types.TypeBindings) (types.Type, *models.InterpreterError) {
+	t, err := we.Object.Type(tb)
+	if err != nil {
+		return nil, err
+	}
+
+	tObj, ok := t.(types.ObjectType)
+	if !ok {
+		return nil, &models.InterpreterError{
+			Message:        fmt.Sprintf("cannot update fields on type %s", t),
+			SourceLocation: we.Object.SourceLocation(),
+		}
+	}
+
+	updateType, err := we.Update.Type(tb)
+	if err != nil {
+		return nil, err
+	}
+	tUpdate, ok := updateType.(types.ObjectType)
+	if !ok {
+		return nil, &models.InterpreterError{
+			Message:        fmt.Sprintf("cannot update fields with type %s", updateType),
+			SourceLocation: we.Update.SourceLocation(),
+		}
+	}
+
+	keys := make([]string, 0, len(tUpdate.Fields))
+	for key := range tUpdate.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldType, ok := tObj.Fields[key]
+		if !ok {
+			return nil, &models.InterpreterError{
+				Message:        "in \"with\" expression",
+				SourceLocation: &we.withLoc,
+				Underlying: &models.InterpreterError{
+					Message:        fmt.Sprintf("field %s not found on type %s", key, t),
+					SourceLocation: we.fieldLocation(key),
+				},
+			}
+		}
+
+		isSuper, innerErr := types.IsSuperTo(fieldType, tUpdate.Fields[key])
+		if innerErr != nil || !isSuper {
+			return nil, &models.InterpreterError{
+				Message:        "in \"with\" expression",
+				SourceLocation: &we.withLoc,
+				Underlying: &models.InterpreterError{
+					Message:        fmt.Sprintf("expected %s for field %s, got %s", fieldType, key, tUpdate.Fields[key]),
+					SourceLocation: we.fieldLocation(key),
+					Underlying:     innerErr,
+				},
+			}
+		}
+	}
+
+	return tObj, nil
+}
+
+// fieldLocation returns the location of the new value of the field, if it
+// is written in an object literal, or else of the update as a whole
+func (we *WithExpression) fieldLocation(key string) *models.SourceLocation {
+	if literal, ok := unwrapped(we.Update).(*ObjectLiteralExpression); ok {
+		if field, ok := literal.Fields[key]; ok {
+			return field.SourceLocation()
+		}
+	}
+	return we.Update.SourceLocation()
+}
+
+func (we *WithExpression) Evaluate(ctx context.Context, bindings expressions.Bindings) (any, *models.InterpreterError) {
+	obj, err := we.Object.Evaluate(ctx, bindings)
+	if err != nil {
+		return nil, err
+	}
+	objMap, ok := obj.(map[string]any)
+	if !ok {
+		return nil, &models.InterpreterError{
+			Message:        fmt.Sprintf("expected object; got %v", obj),
+			SourceLocation: we.Object.SourceLocation(),
+		}
+	}
+
+	update, err := we.Update.Evaluate(ctx, bindings)
+	if err != nil {
+		return nil, err
+	}
+	updateMap, ok := update.(map[string]any)
+	if !ok {
+		return nil, &models.InterpreterError{
+			Message:        fmt.Sprintf("expected object; got %v", update),
+			SourceLocation: we.Update.SourceLocation(),
+		}
+	}
+
+	ret := make(map[string]any, len(objMap))
+	for key, val := range objMap {
+		ret[key] = val
+	}
+	for key, val := range updateMap {
+		ret[key] = val
+	}
+	return ret, nil
+}
+
+func (we *WithExpression) SourceLocation() *models.SourceLocation {
+	return we.Object.SourceLocation()
+}
+
+func (we *WithExpression) String() string {
+	return fmt.Sprintf("(%v with %v)", we.Object, we.Update)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+	"github.com/brandonksides/grundfunken/parser"
+)
+
+// reportFlags are the paths to write reports on the evaluation to, if any
+type reportFlags struct {
+	lcov    string
+	html    string
+	profile string
+}
+
+func bindReportFlags(fs *flag.FlagSet) *reportFlags {
+	rf := &reportFlags{}
+	fs.StringVar(&rf.lcov, "cover-lcov", "", "Write an lcov report of the lines and branches evaluated to this path")
+	fs.StringVar(&rf.html, "cover-html", "", "Write the sources, annotated with the lines evaluated, as HTML to this path")
+	fs.StringVar(&rf.profile, "profile", "", "Write a pprof profile of the calls of each function, and the time spent in them, to this path")
+	return rf
+}
+
+// enable sets opts up to collect what the requested reports need
+func (rf *reportFlags) enable(opts *interpreter.Options) {
+	if rf.lcov != "" || rf.html != "" {
+		opts.Coverage = parser.NewCoverage()
+	}
+	if rf.profile != "" {
+		opts.Profile = interpreter.NewProfiler()
+	}
+}
+
+// write writes the requested reports on the evaluation with opts
+func (rf *reportFlags) write(opts interpreter.Options) error {
+	if rf.lcov != "" {
+		if err := writeFile(rf.lcov, func(w io.Writer) error {
+			return interpreter.WriteLCOV(w, opts.Coverage)
+		}); err != nil {
+			return err
+		}
+	}
+	if rf.html != "" {
+		if err := writeFile(rf.html, func(w io.Writer) error {
+			return interpreter.WriteCoverageHTML(w, opts.Coverage)
+		}); err != nil {
+			return err
+		}
+	}
+	if rf.profile != "" {
+		if err := writeFile(rf.profile, opts.Profile.WriteProfile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
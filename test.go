@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/brandonksides/grundfunken/interpreter"
+)
+
+// test runs the test subcommand, which runs the tests in the test files
+// at or under the paths given as its arguments, returning the exit code
+func test(args []string) int {
+	var verbose bool
+	opts := interpreter.Options{Grants: make(interpreter.Grants)}
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s test [flags] [paths...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	finishOptions := bindOptionFlags(fs, &opts)
+	fs.BoolVar(&verbose, "v", false, "List every test run, rather than only those that fail")
+	reports := bindReportFlags(fs)
+	fs.Parse(args)
+	finishOptions()
+	reports.enable(&opts)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := interpreter.FindTestFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to find test files: %v\n", err)
+		return 1
+	}
+
+	passed, failed := 0, 0
+	for _, file := range files {
+		results, lines, err := interpreter.RunTests(context.Background(), file, opts)
+		if err != nil {
+			fmt.Printf("--- FAIL: %s\n", file)
+			report(err, lines)
+			failed++
+			continue
+		}
+
+		for _, result := range results {
+			if result.Err == nil {
+				passed++
+				if verbose {
+					fmt.Printf("--- PASS: %s: %s (%v)\n", result.File, result.Name, result.Duration)
+				}
+				continue
+			}
+
+			failed++
+			fmt.Printf("--- FAIL: %s: %s (%v)\n", result.File, result.Name, result.Duration)
+			report(result.Err, lines)
+		}
+	}
+
+	if err := reports.write(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+		return 1
+	}
+
+	if failed > 0 {
+		fmt.Printf("FAIL: %d of %d tests failed\n", failed, passed+failed)
+		return 1
+	}
+	fmt.Printf("PASS: %d tests passed\n", passed)
+	return 0
+}
@@ -0,0 +1,53 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+)
+
+// skipBlockComment skips the "/* ... */" comment starting at the given
+// column of the given line, returning the line and column just after it.
+// Block comments nest, so that code containing them can be commented out.
+func skipBlockComment(lines []string, lineNumber int, col int) (int, int, error) {
+	depth := 0
+	for ; lineNumber < len(lines); lineNumber++ {
+		line := lines[lineNumber]
+		for col < len(line) {
+			if strings.HasPrefix(line[col:], "/*") {
+				depth++
+				col += 2
+			} else if strings.HasPrefix(line[col:], "*/") {
+				depth--
+				col += 2
+				if depth == 0 {
+					return lineNumber, col, nil
+				}
+			} else {
+				col++
+			}
+		}
+		col = 0
+	}
+
+	return 0, 0, fmt.Errorf("unterminated block comment")
+}
+
+// attachDocs removes doc comments from the tokens, joining the text of
+// consecutive ones into the Doc of the token after them
+func attachDocs(toks []Token) []Token {
+	ret := make([]Token, 0, len(toks))
+	doc := make([]string, 0)
+	for _, tok := range toks {
+		if tok.Type == DOC_COMMENT {
+			doc = append(doc, tok.Value)
+			continue
+		}
+
+		if len(doc) > 0 {
+			tok.Doc = strings.Join(doc, "\n")
+			doc = doc[:0]
+		}
+		ret = append(ret, tok)
+	}
+	return ret
+}
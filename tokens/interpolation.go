@@ -0,0 +1,55 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonksides/grundfunken/models"
+)
+
+// StringPart is a piece of a string with embedded expressions: either
+// literal text, or the tokens of an expression embedded with "${...}".
+type StringPart struct {
+	Text   string
+	Tokens []Token
+	// SourceLocation is the location of the "${" of an embedded
+	// expression
+	SourceLocation models.SourceLocation
+}
+
+// IsExpression reports whether the part is an embedded expression, rather
+// than literal text.
+func (sp StringPart) IsExpression() bool {
+	return sp.Tokens != nil
+}
+
+// findInterpolationEnd returns the byte offset of the brace closing an
+// expression embedded in a string, which starts at the given offset
+func findInterpolationEnd(line string, start int) (int, error) {
+	depth := 1
+	for col := start; col < len(line); col++ {
+		switch line[col] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return col, nil
+			}
+		case '"', '`':
+			// skip over strings inside the expression, which may
+			// contain braces of their own
+			end := findDelimiter(line, col+1, string(line[col]), line[col] == '"')
+			if end < 0 {
+				return 0, fmt.Errorf("unterminated string in embedded expression")
+			}
+			col = end
+		case '/':
+			if strings.HasPrefix(line[col:], "//") {
+				return 0, fmt.Errorf("unterminated embedded expression")
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated embedded expression")
+}
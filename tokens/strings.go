@@ -0,0 +1,141 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenizeBlockString tokenizes a string that may span several lines,
+// starting at the given column of the given line: either a raw string,
+// between backticks, whose contents are taken exactly as they are, or a
+// string between triple quotes.  It returns the token along with the line
+// and column just after the end of the string.
+func tokenizeBlockString(lines []string, lineNumber int, col int) (Token, int, int, error) {
+	delim := "`"
+	if strings.HasPrefix(lines[lineNumber][col:], `"""`) {
+		delim = `"""`
+	}
+	escapes := delim == `"""`
+
+	// the contents of each line the string spans, as they were written
+	segments := make([]string, 0)
+	raw := delim
+	start := col + len(delim)
+	for lastLine := lineNumber; lastLine < len(lines); lastLine++ {
+		line := lines[lastLine]
+
+		end := findDelimiter(line, start, delim, escapes)
+		if end < 0 {
+			segments = append(segments, line[start:])
+			raw += line[start:] + "\n"
+			start = 0
+			continue
+		}
+
+		segments = append(segments, line[start:end])
+		raw += line[start:end] + delim
+		endCol := end + len(delim)
+
+		if !escapes {
+			return Token{
+				Type:  STRING,
+				Value: strings.Join(segments, "\n"),
+				Raw:   raw,
+			}, lastLine, endCol, nil
+		}
+
+		value, err := unescape(strings.Join(stripIndentation(segments), "\n"))
+		if err != nil {
+			return Token{}, lineNumber, col, err
+		}
+
+		return Token{
+			Type:  STRING,
+			Value: value,
+			Raw:   raw,
+		}, lastLine, endCol, nil
+	}
+
+	return Token{}, lineNumber, col, fmt.Errorf("unterminated string")
+}
+
+// findDelimiter returns the byte offset of the first delimiter in the line
+// at or after start, skipping escaped characters if there are escapes
+func findDelimiter(line string, start int, delim string, escapes bool) int {
+	for i := start; i < len(line); i++ {
+		if escapes && line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(line[i:], delim) {
+			return i
+		}
+	}
+	return -1
+}
+
+// stripIndentation lays out the lines of a triple-quoted string the way
+// they were meant, rather than the way they had to be written to fit in
+// the code around them.  If the opening quotes end their line, the string
+// starts on the next line; if the closing quotes start theirs, it ends at
+// the end of the line before.  The indentation common to the lines in
+// between, and of the closing quotes, is removed from all of them.
+func stripIndentation(segments []string) []string {
+	if len(segments) == 1 {
+		return segments
+	}
+
+	first := segments[0]
+	body := segments[1:]
+	indent := -1
+
+	last := body[len(body)-1]
+	if strings.TrimLeft(last, " \t") == "" {
+		indent = len(last)
+		body = body[:len(body)-1]
+	}
+
+	for _, line := range body {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if lineIndent := len(line) - len(trimmed); indent < 0 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+
+	ret := make([]string, 0, len(segments))
+	if strings.TrimLeft(first, " \t") != "" {
+		ret = append(ret, first)
+	}
+	for _, line := range body {
+		if len(line) < indent {
+			ret = append(ret, strings.TrimLeft(line, " \t"))
+		} else if indent > 0 {
+			ret = append(ret, line[indent:])
+		} else {
+			ret = append(ret, line)
+		}
+	}
+	return ret
+}
+
+// unescape replaces the escape sequences in a string with the characters
+// they stand for
+func unescape(s string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		length, err := writeEscape(&sb, s[i+1:])
+		if err != nil {
+			return "", err
+		}
+		i += length
+	}
+	return sb.String(), nil
+}
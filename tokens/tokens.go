@@ -2,6 +2,10 @@ package tokens
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/brandonksides/grundfunken/models"
 )
@@ -30,6 +34,12 @@ const (
 	EQUAL
 	PERCENT
 	DOT
+	// PIPELINE is "|>", which passes the value on its left to the function
+	// on its right
+	PIPELINE
+	// ELLIPSIS is "...", which stands for the rest of a list, or spreads a
+	// list or object into a literal
+	ELLIPSIS
 
 	// Type Operators
 	PIPE
@@ -55,6 +65,12 @@ const (
 	ON
 	CASE
 	AS
+	CHAN
+	WITH
+
+	// DOC_COMMENT tokens only exist while tokenizing; their text ends up
+	// in the Doc of the token that follows them
+	DOC_COMMENT
 )
 
 var tokMap = map[string]TokenType{
@@ -92,11 +108,24 @@ var tokMap = map[string]TokenType{
 	"as":    AS,
 	"case":  CASE,
 	"on":    ON,
+	"chan":  CHAN,
+	"with":  WITH,
 }
 
 type Token struct {
-	Type           TokenType
-	Value          string
+	Type TokenType
+	// Value is the meaning of the token, e.g. the contents of a string
+	// literal after escapes are replaced, or the digits of a number
+	// without separators
+	Value string
+	// Raw is the token exactly as it was spelled in the source
+	Raw string
+	// Parts holds the pieces of a string with embedded expressions, in
+	// order; it is empty for other tokens
+	Parts []StringPart
+	// Doc is the text of the "///" comments just before the token, if
+	// any, one line per comment
+	Doc            string
 	SourceLocation models.SourceLocation
 }
 
@@ -105,6 +134,15 @@ type TokenStack struct {
 	curLoc models.SourceLocation
 }
 
+// NewTokenStack returns a stack of the given tokens; the location is
+// reported as the current location if the stack is empty.
+func NewTokenStack(toks []Token, loc models.SourceLocation) *TokenStack {
+	return &TokenStack{
+		toks:   toks,
+		curLoc: loc,
+	}
+}
+
 func (stack *TokenStack) CurrentSourceLocation() *models.SourceLocation {
 	ret := stack.curLoc
 	return &ret
@@ -120,11 +158,7 @@ func (stack *TokenStack) Pop() (Token, error) {
 	this, stack.toks = stack.toks[0], stack.toks[1:]
 
 	if len(stack.toks) == 0 {
-		stack.curLoc = models.SourceLocation{
-			LineNumber:   this.SourceLocation.LineNumber + len(this.Value),
-			ColumnNumber: this.SourceLocation.ColumnNumber,
-			File:         this.SourceLocation.File,
-		}
+		stack.curLoc = this.end()
 	} else {
 		stack.curLoc = stack.toks[0].SourceLocation
 	}
@@ -132,6 +166,21 @@ func (stack *TokenStack) Pop() (Token, error) {
 	return this, nil
 }
 
+// end returns the location just after the token, which may be on a later
+// line than its start for multi-line strings
+func (tok Token) end() models.SourceLocation {
+	ret := tok.SourceLocation
+	lastNewline := strings.LastIndex(tok.Raw, "\n")
+	if lastNewline < 0 {
+		ret.ColumnNumber += utf8.RuneCountInString(tok.Raw)
+		return ret
+	}
+
+	ret.LineNumber += strings.Count(tok.Raw, "\n")
+	ret.ColumnNumber = utf8.RuneCountInString(tok.Raw[lastNewline+1:])
+	return ret
+}
+
 // Peek returns the next token in the stack without removing it
 func (stack *TokenStack) Peek() (Token, bool) {
 	if len(stack.toks) == 0 {
@@ -144,186 +193,421 @@ func (stack *TokenStack) Peek() (Token, bool) {
 func Tokenize(filename string, lines []string) (*TokenStack, *models.InterpreterError) {
 	toks := make([]Token, 0)
 
-	for lineNumber, line := range lines {
-		lineToks, err := tokenizeLine(filename, line, lineNumber)
+	for lineNumber := 0; lineNumber < len(lines); lineNumber++ {
+		lineToks, lastLine, err := tokenizeLine(filename, lines, lineNumber)
 		if err != nil {
 			return nil, err
 		}
 
 		toks = append(toks, lineToks...)
+		// tokens like multi-line strings continue onto the following
+		// lines, which are then finished by tokenizeLine
+		lineNumber = lastLine
 	}
 
 	return &TokenStack{
-		toks: toks,
+		toks: attachDocs(toks),
 		curLoc: models.SourceLocation{
 			File: filename,
 		},
 	}, nil
 }
 
-func tokenizeLine(file string, line string, lineNumber int) ([]Token, *models.InterpreterError) {
+// tokenizeLine tokenizes the line at the given index, and any following
+// lines that tokens on it continue onto, returning the index of the last
+// line tokenized
+func tokenizeLine(file string, lines []string, lineNumber int) ([]Token, int, *models.InterpreterError) {
+	return tokenizeSpan(file, lines, lineNumber, 0, len(lines[lineNumber]))
+}
+
+// tokenizeSpan tokenizes the line at the given index between the given
+// byte offsets, continuing onto the following lines only if the span
+// reaches the end of the line
+func tokenizeSpan(file string, lines []string, lineNumber int, col int, end int) ([]Token, int, *models.InterpreterError) {
+	line := lines[lineNumber][:end]
 	toks := make([]Token, 0)
-	col := 0
+	// col is a byte offset into the line, but source locations count
+	// columns in runes
 	for col < len(line) {
-		char := line[col]
+		char, size := utf8.DecodeRuneInString(line[col:])
 		if char == ' ' || char == '\t' {
-			col++
+			col += size
 			continue
-		} else if char == '/' {
-			if col+1 < len(line) && line[col+1] == '/' {
-				break
+		} else if strings.HasPrefix(line[col:], "///") {
+			toks = append(toks, Token{
+				Type:  DOC_COMMENT,
+				Value: strings.TrimPrefix(line[col+3:], " "),
+				Raw:   line[col:],
+				SourceLocation: models.SourceLocation{
+					File:         file,
+					LineNumber:   lineNumber,
+					ColumnNumber: utf8.RuneCountInString(line[:col]),
+				},
+			})
+			break
+		} else if strings.HasPrefix(line[col:], "//") {
+			break
+		} else if strings.HasPrefix(line[col:], "/*") {
+			lastLine, endCol, err := skipBlockComment(lines, lineNumber, col)
+			if err == nil && lastLine != lineNumber && len(line) != len(lines[lineNumber]) {
+				err = fmt.Errorf("multi-line comments cannot be embedded in strings")
 			}
+			if err != nil {
+				return nil, lineNumber, &models.InterpreterError{
+					Underlying: err,
+					SourceLocation: &models.SourceLocation{
+						File:         file,
+						LineNumber:   lineNumber,
+						ColumnNumber: utf8.RuneCountInString(line[:col]),
+					},
+				}
+			}
+
+			if lastLine != lineNumber {
+				lineNumber, line = lastLine, lines[lastLine]
+			}
+			col = endCol
+			continue
 		}
-		if tokType, ok := tokMap[string(char)]; ok {
+		if strings.HasPrefix(line[col:], "|>") {
+			// "|" alone separates the members of union types
+			toks = append(toks, Token{
+				Type:  PIPELINE,
+				Value: "|>",
+				Raw:   "|>",
+				SourceLocation: models.SourceLocation{
+					File:         file,
+					LineNumber:   lineNumber,
+					ColumnNumber: utf8.RuneCountInString(line[:col]),
+				},
+			})
+			col += 2
+		} else if strings.HasPrefix(line[col:], "...") {
+			toks = append(toks, Token{
+				Type:  ELLIPSIS,
+				Value: "...",
+				Raw:   "...",
+				SourceLocation: models.SourceLocation{
+					File:         file,
+					LineNumber:   lineNumber,
+					ColumnNumber: utf8.RuneCountInString(line[:col]),
+				},
+			})
+			col += 3
+		} else if tokType, ok := tokMap[string(char)]; ok {
 			toks = append(toks, Token{
 				Type:  tokType,
 				Value: string(char),
+				Raw:   string(char),
 				SourceLocation: models.SourceLocation{
 					File:         file,
 					LineNumber:   lineNumber,
-					ColumnNumber: col,
+					ColumnNumber: utf8.RuneCountInString(line[:col]),
 				},
 			})
-			col++
-		} else if char == '"' {
-			strTok, length, err := tokenizeString(line[col:])
+			col += size
+		} else if char == '"' && strings.HasPrefix(line[col:], `"""`) || char == '`' {
+			strTok, lastLine, endCol, err := tokenizeBlockString(lines, lineNumber, col)
+			if err == nil && lastLine != lineNumber && len(line) != len(lines[lineNumber]) {
+				err = fmt.Errorf("multi-line strings cannot be embedded in strings")
+			}
 			if err != nil {
-				return nil, &models.InterpreterError{
+				return nil, lineNumber, &models.InterpreterError{
 					Underlying: err,
 					SourceLocation: &models.SourceLocation{
 						File:         file,
 						LineNumber:   lineNumber,
-						ColumnNumber: col + length,
+						ColumnNumber: utf8.RuneCountInString(line[:col]),
 					},
 				}
 			}
 			strTok.SourceLocation = models.SourceLocation{
 				File:         file,
 				LineNumber:   lineNumber,
-				ColumnNumber: col,
+				ColumnNumber: utf8.RuneCountInString(line[:col]),
+			}
+			toks = append(toks, strTok)
+
+			lineNumber, line, col = lastLine, lines[lastLine], endCol
+		} else if char == '"' {
+			strTok, length, err := tokenizeString(file, lines, lineNumber, col, len(line))
+			if err != nil {
+				return nil, lineNumber, err
+			}
+			strTok.SourceLocation = models.SourceLocation{
+				File:         file,
+				LineNumber:   lineNumber,
+				ColumnNumber: utf8.RuneCountInString(line[:col]),
 			}
+			strTok.Raw = line[col : col+length]
 			col += length
 			toks = append(toks, strTok)
 		} else if char == '-' || (char >= '0' && char <= '9') {
 			numTok, length, err := tokenizeNumber(line[col:])
 			if err != nil {
-				return nil, &models.InterpreterError{
+				return nil, lineNumber, &models.InterpreterError{
 					Underlying: err,
 					SourceLocation: &models.SourceLocation{
 						File:         file,
 						LineNumber:   lineNumber,
-						ColumnNumber: col + length,
+						ColumnNumber: utf8.RuneCountInString(line[:col+length]),
 					},
 				}
 			}
 			numTok.SourceLocation = models.SourceLocation{
 				File:         file,
 				LineNumber:   lineNumber,
-				ColumnNumber: col,
+				ColumnNumber: utf8.RuneCountInString(line[:col]),
 			}
+			numTok.Raw = line[col : col+length]
 			col += length
 			toks = append(toks, numTok)
-		} else if char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char == '_' {
+		} else if unicode.IsLetter(char) || char == '_' {
 			idTok, length, err := tokenizeOther(line[col:])
 			if err != nil {
-				return nil, &models.InterpreterError{
+				return nil, lineNumber, &models.InterpreterError{
 					Underlying: err,
 					SourceLocation: &models.SourceLocation{
 						File:         file,
 						LineNumber:   lineNumber,
-						ColumnNumber: col + length,
+						ColumnNumber: utf8.RuneCountInString(line[:col+length]),
 					},
 				}
 			}
 			idTok.SourceLocation = models.SourceLocation{
 				File:         file,
 				LineNumber:   lineNumber,
-				ColumnNumber: col,
+				ColumnNumber: utf8.RuneCountInString(line[:col]),
 			}
+			idTok.Raw = line[col : col+length]
 			col += length
 			toks = append(toks, idTok)
 		} else {
-			return nil, &models.InterpreterError{
+			return nil, lineNumber, &models.InterpreterError{
 				Underlying: fmt.Errorf("unexpected character %c", char),
 				SourceLocation: &models.SourceLocation{
 					File:         file,
 					LineNumber:   lineNumber,
-					ColumnNumber: col,
+					ColumnNumber: utf8.RuneCountInString(line[:col]),
 				},
 			}
 		}
 	}
 
-	return toks, nil
+	return toks, lineNumber, nil
 }
 
-func tokenizeString(line string) (Token, int, error) {
-	col := 1
+// tokenizeString tokenizes the string starting at the given byte offset
+// of the line, returning its length.  Expressions embedded in the string
+// are tokenized in place, so their tokens have their actual locations.
+func tokenizeString(file string, lines []string, lineNumber int, start int, end int) (Token, int, *models.InterpreterError) {
+	line := lines[lineNumber][:end]
+	loc := func(col int) *models.SourceLocation {
+		// errors may be found in the middle of a multi-byte rune, but
+		// are reported at its start
+		for col > 0 && col < len(line) && !utf8.RuneStart(line[col]) {
+			col--
+		}
+		return &models.SourceLocation{
+			File:         file,
+			LineNumber:   lineNumber,
+			ColumnNumber: utf8.RuneCountInString(line[:col]),
+		}
+	}
 
-	lineTokVal := ""
+	col := start + 1
+	parts := make([]StringPart, 0)
+	var lineTokVal strings.Builder
 	for col < len(line) {
 		if line[col] == '"' {
+			if len(parts) == 0 {
+				return Token{
+					Type:  STRING,
+					Value: lineTokVal.String(),
+				}, col + 1 - start, nil
+			}
+
+			if lineTokVal.Len() > 0 {
+				parts = append(parts, StringPart{Text: lineTokVal.String()})
+			}
 			return Token{
 				Type:  STRING,
-				Value: lineTokVal,
-			}, col + 1, nil
+				Parts: parts,
+			}, col + 1 - start, nil
 		} else if line[col] == '\\' {
 			col++
 			if col >= len(line) {
 				break
 			}
-			escaped := line[col]
-			switch escaped {
-			case 'n':
-				lineTokVal += "\n"
-			case 't':
-				lineTokVal += "\t"
-			case 'r':
-				lineTokVal += "\r"
-			case '\\':
-				lineTokVal += "\\"
-			case '"':
-				lineTokVal += "\""
-			default:
-				return Token{}, col, fmt.Errorf("unexpected escape character %c", escaped)
+			length, err := writeEscape(&lineTokVal, line[col:])
+			if err != nil {
+				return Token{}, 0, &models.InterpreterError{
+					Underlying:     err,
+					SourceLocation: loc(col + length),
+				}
+			}
+			col += length - 1
+		} else if strings.HasPrefix(line[col:], "${") {
+			exprEnd, err := findInterpolationEnd(line, col+2)
+			if err != nil {
+				return Token{}, 0, &models.InterpreterError{
+					Underlying:     err,
+					SourceLocation: loc(col),
+				}
+			}
+
+			toks, _, innerErr := tokenizeSpan(file, lines, lineNumber, col+2, exprEnd)
+			if innerErr != nil {
+				return Token{}, 0, innerErr
 			}
+
+			if lineTokVal.Len() > 0 {
+				parts = append(parts, StringPart{Text: lineTokVal.String()})
+				lineTokVal.Reset()
+			}
+			parts = append(parts, StringPart{
+				Tokens:         toks,
+				SourceLocation: *loc(col),
+			})
+			col = exprEnd
 		} else {
-			lineTokVal += string(line[col])
+			// bytes are copied as they are, so multi-byte runes are
+			// copied whole
+			lineTokVal.WriteByte(line[col])
 		}
 		col++
 	}
 
-	return Token{}, col - 1, fmt.Errorf("unterminated string")
+	return Token{}, 0, &models.InterpreterError{
+		Underlying:     fmt.Errorf("unterminated string"),
+		SourceLocation: loc(col - 1),
+	}
+}
+
+// writeEscape writes the character of the escape sequence at the start of
+// line, which follows a backslash, returning the length of the sequence
+func writeEscape(sb *strings.Builder, line string) (int, error) {
+	escaped, _ := utf8.DecodeRuneInString(line)
+	switch escaped {
+	case 'n':
+		sb.WriteByte('\n')
+	case 't':
+		sb.WriteByte('\t')
+	case 'r':
+		sb.WriteByte('\r')
+	case '\\':
+		sb.WriteByte('\\')
+	case '"':
+		sb.WriteByte('"')
+	case '$':
+		sb.WriteByte('$')
+	case 'u':
+		r, length, err := tokenizeUnicodeEscape(line[1:])
+		if err != nil {
+			return 1 + length, err
+		}
+		sb.WriteRune(r)
+		return 1 + length, nil
+	default:
+		return 0, fmt.Errorf("unexpected escape character %c", escaped)
+	}
+	return 1, nil
+}
+
+// tokenizeUnicodeEscape reads the "{...}" of a "\u{...}" escape, containing
+// the hexadecimal code point of a rune
+func tokenizeUnicodeEscape(line string) (rune, int, error) {
+	if !strings.HasPrefix(line, "{") {
+		return 0, 0, fmt.Errorf("expected { after \\u")
+	}
+
+	end := strings.IndexByte(line, '}')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("unterminated unicode escape")
+	}
+
+	digits := line[1:end]
+	if len(digits) == 0 || len(digits) > 6 || strings.IndexFunc(digits, func(r rune) bool { return r > unicode.MaxASCII || !isHexDigit(byte(r)) }) >= 0 {
+		return 0, 1, fmt.Errorf("unicode escape must contain 1 to 6 hexadecimal digits")
+	}
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, 1, fmt.Errorf("invalid unicode code point %s", digits)
+	}
+
+	return rune(code), end + 1, nil
 }
 
 func tokenizeNumber(line string) (Token, int, error) {
-	col := 0
+	isDigit := isDecimalDigit
+	prefixLen := 0
+	if len(line) >= 2 && line[0] == '0' {
+		switch line[1] {
+		case 'x', 'X':
+			isDigit, prefixLen = isHexDigit, 2
+		case 'b', 'B':
+			isDigit, prefixLen = isBinaryDigit, 2
+		case 'o', 'O':
+			isDigit, prefixLen = isOctalDigit, 2
+		}
+	}
+	col := prefixLen
+	// only decimal numbers have fractional parts
+	allowDot := prefixLen == 0
+
+	value := line[:prefixLen]
 	for col < len(line) {
-		if (line[col] < '0' || line[col] > '9') && line[col] != '.' {
-			if line[col] >= 'a' && line[col] <= 'z' || line[col] >= 'A' && line[col] <= 'Z' || line[col] == '_' {
-				return Token{}, col, fmt.Errorf("unexpected character %c", line[col])
+		char := line[col]
+		if isDigit(char) || allowDot && char == '.' {
+			value += string(char)
+		} else if char == '_' {
+			// separators must come between two digits
+			if col == prefixLen || !isDigit(line[col-1]) || col+1 >= len(line) || !isDigit(line[col+1]) {
+				return Token{}, col, fmt.Errorf("digit separator must come between two digits")
 			}
-			return Token{
-				Type:  NUMBER,
-				Value: line[:col],
-			}, col, nil
+		} else if r, _ := utf8.DecodeRuneInString(line[col:]); unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return Token{}, col, fmt.Errorf("unexpected character %c in number", r)
+		} else {
+			break
 		}
 		col++
 	}
 
+	if col == prefixLen {
+		return Token{}, col, fmt.Errorf("expected digits after %s", line[:prefixLen])
+	}
+
 	return Token{
 		Type:  NUMBER,
-		Value: line[:col],
+		Value: value,
 	}, col, nil
 }
 
+func isDecimalDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func isHexDigit(char byte) bool {
+	return isDecimalDigit(char) || char >= 'a' && char <= 'f' || char >= 'A' && char <= 'F'
+}
+
+func isBinaryDigit(char byte) bool {
+	return char == '0' || char == '1'
+}
+
+func isOctalDigit(char byte) bool {
+	return char >= '0' && char <= '7'
+}
+
 func tokenizeOther(line string) (Token, int, error) {
 	col := 0
 	for col < len(line) {
-		if (line[col] < 'a' || line[col] > 'z') && (line[col] < 'A' || line[col] > 'Z') && line[col] != '_' && (line[col] < '0' || line[col] > '9') {
+		char, size := utf8.DecodeRuneInString(line[col:])
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) && char != '_' {
 			break
 		}
-		col++
+		col += size
 	}
 
 	word := line[:col]
@@ -0,0 +1,333 @@
+package tokens
+
+import (
+	"testing"
+)
+
+func TestTokenizeNumber(t *testing.T) {
+	tests := []struct {
+		src   string
+		value string
+		err   string
+		// col is the column of the error, counted from zero
+		col int
+	}{
+		{src: "42", value: "42"},
+		{src: "1_000_000", value: "1000000"},
+		{src: "3.14", value: "3.14"},
+		{src: "1_000.5", value: "1000.5"},
+		{src: "0x1F", value: "0x1F"},
+		{src: "0XfF", value: "0XfF"},
+		{src: "0xdead_beef", value: "0xdeadbeef"},
+		{src: "0b1010", value: "0b1010"},
+		{src: "0b1111_0000", value: "0b11110000"},
+		{src: "0o17", value: "0o17"},
+		{src: "0x", err: "expected digits after 0x", col: 2},
+		{src: "0b", err: "expected digits after 0b", col: 2},
+		{src: "0x_1", err: "digit separator must come between two digits", col: 2},
+		{src: "1__0", err: "digit separator must come between two digits", col: 1},
+		{src: "1_", err: "digit separator must come between two digits", col: 1},
+		{src: "1_.5", err: "digit separator must come between two digits", col: 1},
+		{src: "0b2", err: "unexpected character 2 in number", col: 2},
+		{src: "0o8", err: "unexpected character 8 in number", col: 2},
+		{src: "0xG", err: "unexpected character G in number", col: 2},
+		{src: "12abc", err: "unexpected character a in number", col: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			toks, err := Tokenize("numbers.gf", []string{test.src})
+			if test.err != "" {
+				if err == nil {
+					t.Fatalf("expected error %q; got tokens %v", test.err, toks.toks)
+				}
+				if err.Underlying == nil || err.Underlying.Error() != test.err {
+					t.Errorf("expected error %q; got %v", test.err, err.Underlying)
+				}
+				if err.SourceLocation.ColumnNumber != test.col {
+					t.Errorf("expected error at column %d; got %d", test.col, err.SourceLocation.ColumnNumber)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err.Underlying)
+			}
+
+			if len(toks.toks) != 1 {
+				t.Fatalf("expected a single token; got %v", toks.toks)
+			}
+			tok := toks.toks[0]
+			if tok.Type != NUMBER || tok.Value != test.value || tok.Raw != test.src {
+				t.Errorf("expected number %s spelled %s; got %v", test.value, test.src, tok)
+			}
+		})
+	}
+}
+
+func TestTokenizeString(t *testing.T) {
+	tests := []struct {
+		src   string
+		value string
+		err   string
+		// col is the column of the error, counted in runes from zero
+		col int
+	}{
+		{src: `"héllo"`, value: "héllo"},
+		{src: `"a\tb\n\"c\"\\"`, value: "a\tb\n\"c\"\\"},
+		{src: `"\u{48}\u{e9}\u{1F600}"`, value: "Hé😀"},
+		{src: `"\u{10FFFF}"`, value: "\U0010FFFF"},
+		{src: `"\$"`, value: "$"},
+		{src: `"é\q"`, err: "unexpected escape character q", col: 3},
+		{src: `"\u41"`, err: "expected { after \\u", col: 3},
+		{src: `"\u{41"`, err: "unterminated unicode escape", col: 3},
+		{src: `"\u{}"`, err: "unicode escape must contain 1 to 6 hexadecimal digits", col: 4},
+		{src: `"\u{1234567}"`, err: "unicode escape must contain 1 to 6 hexadecimal digits", col: 4},
+		{src: `"\u{zz}"`, err: "unicode escape must contain 1 to 6 hexadecimal digits", col: 4},
+		{src: `"\u{110000}"`, err: "invalid unicode code point 110000", col: 4},
+		{src: `"\u{D800}"`, err: "invalid unicode code point D800", col: 4},
+		{src: `"日本`, err: "unterminated string", col: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			toks, err := Tokenize("strings.gf", []string{test.src})
+			if test.err != "" {
+				if err == nil {
+					t.Fatalf("expected error %q; got tokens %v", test.err, toks.toks)
+				}
+				if err.Underlying == nil || err.Underlying.Error() != test.err {
+					t.Errorf("expected error %q; got %v", test.err, err.Underlying)
+				}
+				if err.SourceLocation.ColumnNumber != test.col {
+					t.Errorf("expected error at column %d; got %d", test.col, err.SourceLocation.ColumnNumber)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err.Underlying)
+			}
+
+			if len(toks.toks) != 1 {
+				t.Fatalf("expected a single token; got %v", toks.toks)
+			}
+			tok := toks.toks[0]
+			if tok.Type != STRING || tok.Value != test.value || tok.Raw != test.src {
+				t.Errorf("expected string %q spelled %s; got %v", test.value, test.src, tok)
+			}
+		})
+	}
+}
+
+func TestTokenizeUnicodeIdentifiers(t *testing.T) {
+	toks, err := Tokenize("identifiers.gf", []string{"größe + 日本"})
+	if err != nil {
+		t.Fatal(err.Underlying)
+	}
+
+	want := []struct {
+		typ   TokenType
+		value string
+		col   int
+	}{
+		{IDENTIFIER, "größe", 0},
+		{PLUS, "+", 6},
+		{IDENTIFIER, "日本", 8},
+	}
+	if len(toks.toks) != len(want) {
+		t.Fatalf("expected %d tokens; got %v", len(want), toks.toks)
+	}
+	for i, tok := range toks.toks {
+		if tok.Type != want[i].typ || tok.Value != want[i].value || tok.SourceLocation.ColumnNumber != want[i].col {
+			t.Errorf("expected %s at column %d; got %v", want[i].value, want[i].col, tok)
+		}
+	}
+}
+
+func TestTokenizeBlockString(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		value string
+		err   string
+		// line and col are where the error is reported, counted from zero
+		line, col int
+	}{
+		{
+			name:  "triple quotes on one line",
+			lines: []string{`"""say "hi"\n"""`},
+			value: "say \"hi\"\n",
+		},
+		{
+			name: "indentation removed",
+			lines: []string{
+				`let s = """`,
+				`        a`,
+				`          b`,
+				``,
+				`        c`,
+				`        """`,
+			},
+			value: "a\n  b\n\nc",
+		},
+		{
+			name: "closing quotes less indented",
+			lines: []string{
+				`"""`,
+				`    a`,
+				`  """`,
+			},
+			value: "  a",
+		},
+		{
+			name: "text after the opening quotes",
+			lines: []string{
+				`"""first`,
+				`  second"""`,
+			},
+			value: "first\nsecond",
+		},
+		{
+			name:  "raw",
+			lines: []string{"`C:\\path\\n ${x} \"q\"`"},
+			value: `C:\path\n ${x} "q"`,
+		},
+		{
+			name: "raw lines kept as they are",
+			lines: []string{
+				"`",
+				"    a",
+				"    `",
+			},
+			value: "\n    a\n    ",
+		},
+		{
+			// expressions are only embedded in "..." strings
+			name:  "triple quotes keep ${}",
+			lines: []string{`"""${x} \${y}"""`},
+			value: "${x} ${y}",
+		},
+		{
+			name:  "raw string keeps ${}",
+			lines: []string{"`${x}`"},
+			value: "${x}",
+		},
+		{
+			name:  "unterminated triple quotes",
+			lines: []string{`x + """abc`, `def`},
+			err:   "unterminated string",
+			col:   4,
+		},
+		{
+			name:  "unterminated raw string",
+			lines: []string{"`abc"},
+			err:   "unterminated string",
+		},
+		{
+			name:  "bad escape in triple quotes",
+			lines: []string{`1`, ` """\q"""`},
+			err:   "unexpected escape character q",
+			line:  1,
+			col:   1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toks, err := Tokenize("strings.gf", test.lines)
+			if test.err != "" {
+				if err == nil {
+					t.Fatalf("expected error %q; got tokens %v", test.err, toks.toks)
+				}
+				if err.Underlying == nil || err.Underlying.Error() != test.err {
+					t.Errorf("expected error %q; got %v", test.err, err.Underlying)
+				}
+				if loc := err.SourceLocation; loc.LineNumber != test.line || loc.ColumnNumber != test.col {
+					t.Errorf("expected error at line %d, column %d; got line %d, column %d", test.line, test.col, loc.LineNumber, loc.ColumnNumber)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err.Underlying)
+			}
+
+			last := toks.toks[len(toks.toks)-1]
+			if last.Type != STRING || last.Value != test.value || len(last.Parts) > 0 {
+				t.Errorf("expected string %q; got %v", test.value, last)
+			}
+		})
+	}
+}
+
+func TestTokenizeInterpolation(t *testing.T) {
+	toks, err := Tokenize("interpolation.gf", []string{`"é ${x + 1}, ${"}"}\${y}"`})
+	if err != nil {
+		t.Fatal(err.Underlying)
+	}
+	if len(toks.toks) != 1 {
+		t.Fatalf("expected a single token; got %v", toks.toks)
+	}
+
+	parts := toks.toks[0].Parts
+	if len(parts) != 5 {
+		t.Fatalf("expected 5 parts; got %v", parts)
+	}
+	if parts[0].IsExpression() || parts[0].Text != "é " {
+		t.Errorf("expected text \"é \"; got %v", parts[0])
+	}
+	if !parts[1].IsExpression() || parts[1].SourceLocation.ColumnNumber != 3 {
+		t.Errorf("expected expression at column 3; got %v", parts[1])
+	}
+	// the embedded tokens have their locations in the line
+	wantCols := []int{5, 7, 9}
+	if len(parts[1].Tokens) != len(wantCols) {
+		t.Fatalf("expected %d tokens in the expression; got %v", len(wantCols), parts[1].Tokens)
+	}
+	for i, tok := range parts[1].Tokens {
+		if tok.SourceLocation.ColumnNumber != wantCols[i] {
+			t.Errorf("expected %s at column %d; got %d", tok.Value, wantCols[i], tok.SourceLocation.ColumnNumber)
+		}
+	}
+	if parts[2].IsExpression() || parts[2].Text != ", " {
+		t.Errorf("expected text \", \"; got %v", parts[2])
+	}
+	// braces in strings don't close the expression
+	if !parts[3].IsExpression() || len(parts[3].Tokens) != 1 || parts[3].Tokens[0].Value != "}" {
+		t.Errorf("expected expression of the string \"}\"; got %v", parts[3])
+	}
+	// and "\$" is a dollar sign rather than the start of an expression
+	if parts[4].IsExpression() || parts[4].Text != "${y}" {
+		t.Errorf("expected text \"${y}\"; got %v", parts[4])
+	}
+	if toks.toks[0].Value != "" {
+		t.Errorf("expected no value for a string with embedded expressions; got %q", toks.toks[0].Value)
+	}
+}
+
+func TestTokenizeInterpolationErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+		// col is the column of the error, counted in runes from zero
+		col int
+	}{
+		{src: `"é ${x"`, err: "unterminated string in embedded expression", col: 3},
+		{src: `"é ${x`, err: "unterminated embedded expression", col: 3},
+		{src: `"é ${x // }"`, err: "unterminated embedded expression", col: 3},
+		{src: `"é ${x ? 1}"`, err: "unexpected character ?", col: 7},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			toks, err := Tokenize("interpolation.gf", []string{test.src})
+			if err == nil {
+				t.Fatalf("expected error %q; got tokens %v", test.err, toks.toks)
+			}
+			if err.Underlying == nil || err.Underlying.Error() != test.err {
+				t.Errorf("expected error %q; got %v", test.err, err.Underlying)
+			}
+			if err.SourceLocation.ColumnNumber != test.col {
+				t.Errorf("expected error at column %d; got %d", test.col, err.SourceLocation.ColumnNumber)
+			}
+		})
+	}
+}